@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"blockmesh/config"
+	"blockmesh/internal/logging"
+	"blockmesh/runner"
+	"blockmesh/signer"
+)
+
+// apiServer 本地管理 API，只允许监听回环地址并要求 Token 认证
+type apiServer struct {
+	runner *runner.Runner
+	token  string
+	ctx    context.Context
+}
+
+// serveAPI 启动管理 API，直到 ctx 被取消
+func serveAPI(ctx context.Context, r *runner.Runner, cfg config.APIConfig) error {
+	if err := config.CheckLoopback(cfg.Listen); err != nil {
+		return err
+	}
+	token := cfg.Token
+	if token == "" {
+		token = os.Getenv("COINSHIFT_API_TOKEN")
+	}
+	if token == "" {
+		return fmt.Errorf("管理 API 必须配置 token (api.token 或环境变量 COINSHIFT_API_TOKEN)")
+	}
+
+	s := &apiServer{runner: r, token: token, ctx: ctx}
+	server := &http.Server{
+		Addr:              cfg.Listen,
+		Handler:           s.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		logging.Start("%s 管理 API 已启动: http://%s", logging.IconNetwork, cfg.Listen)
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		logging.Info("正在关闭管理 API...")
+		r.Cancel()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	}
+}
+
+// handler 返回带 Token 认证的路由
+func (s *apiServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/accounts", s.handleAccounts)
+	mux.HandleFunc("GET /api/status", s.handleStatus)
+	mux.HandleFunc("POST /api/run", s.handleRun)
+	mux.HandleFunc("POST /api/cancel", s.handleCancel)
+	mux.HandleFunc("POST /api/reload", s.handleReload)
+	return s.authenticate(mux)
+}
+
+// authenticate 校验 Authorization: Bearer <token>
+func (s *apiServer) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "未授权"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *apiServer) handleAccounts(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.runner.Accounts())
+}
+
+func (s *apiServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	status := map[string]interface{}{
+		"running":  s.runner.Running(),
+		"accounts": len(s.runner.Config().Accounts),
+	}
+	if last := s.runner.LastRunAt(); !last.IsZero() {
+		status["last_run_at"] = last
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleRun 触发一次执行，可通过 ?address= 只执行单个账户
+func (s *apiServer) handleRun(w http.ResponseWriter, r *http.Request) {
+	var filter runner.AccountFilter
+	if address := r.URL.Query().Get("address"); address != "" {
+		found := false
+		for _, account := range s.runner.Accounts() {
+			if strings.EqualFold(account.Address, address) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "未找到该地址对应的账户"})
+			return
+		}
+		filter = func(_ int, account config.AccountConfig) bool {
+			accountAddress, err := signer.GetAddressFromPrivateKey(account.PrivateKey)
+			return err == nil && strings.EqualFold(accountAddress, address)
+		}
+	}
+
+	err := s.runner.Start(s.ctx, filter, func(results []*runner.AccountResult) {
+		logging.Success("所有账户处理完成")
+	})
+	if err != nil {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "started"})
+}
+
+func (s *apiServer) handleCancel(w http.ResponseWriter, r *http.Request) {
+	if !s.runner.Cancel() {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "当前没有正在执行的任务"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "cancelling"})
+}
+
+func (s *apiServer) handleReload(w http.ResponseWriter, r *http.Request) {
+	cfg, err := s.runner.Reload()
+	if err != nil {
+		code := http.StatusBadRequest
+		if errors.Is(err, runner.ErrRunInProgress) {
+			code = http.StatusConflict
+		}
+		writeJSON(w, code, map[string]string{"error": err.Error()})
+		return
+	}
+	logging.Success("配置已重新加载，共 %d 个账户", len(cfg.Accounts))
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "reloaded", "accounts": len(cfg.Accounts)})
+}
+
+// writeJSON 输出 JSON 响应
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"blockmesh/config"
+	"blockmesh/fakeserver"
+	"blockmesh/runner"
+)
+
+func TestAPIAccountsIndex(t *testing.T) {
+	_, ts := newTestServer(t, fakeserver.Options{})
+	configPath, _ := writeTestConfig(t, ts.URL, 2, nil)
+	cfg, err := config.Load(configPath, config.LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := runner.New(configPath, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Run(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	api := httptest.NewServer((&apiServer{runner: r, token: "t", ctx: context.Background()}).handler())
+	defer api.Close()
+	req, _ := http.NewRequest("GET", api.URL+"/api/accounts", nil)
+	req.Header.Set("Authorization", "Bearer t")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var accounts []struct {
+		Index      int `json:"index"`
+		LastResult *struct {
+			Index int `json:"index"`
+		} `json:"last_result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&accounts); err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 2 {
+		t.Fatalf("返回 %d 个账户，期望 2 个", len(accounts))
+	}
+	for i, account := range accounts {
+		if account.Index != i+1 {
+			t.Errorf("第 %d 个账户的 index 为 %d", i+1, account.Index)
+		}
+		if account.LastResult == nil || account.LastResult.Index != account.Index {
+			t.Errorf("第 %d 个账户的 last_result.index 与 index 不一致: %+v", i+1, account.LastResult)
+		}
+	}
+}
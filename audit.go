@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"blockmesh/signer"
+)
+
+// auditTimeLayout 审计记录时间的输出格式
+const auditTimeLayout = "2006-01-02 15:04:05"
+
+// printAuditSummary 按地址汇总签名次数、用途与首次/最近签名时间
+func printAuditSummary(w io.Writer, entries []signer.AuditEntry) error {
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "签名审计日志为空")
+		return nil
+	}
+
+	type summary struct {
+		address  string
+		count    int
+		purposes map[string]int
+		first    signer.AuditEntry
+		last     signer.AuditEntry
+	}
+	byAddress := make(map[string]*summary)
+	for _, entry := range entries {
+		key := strings.ToLower(entry.Address)
+		s, ok := byAddress[key]
+		if !ok {
+			s = &summary{address: entry.Address, purposes: make(map[string]int), first: entry}
+			byAddress[key] = s
+		}
+		s.count++
+		s.purposes[entry.Purpose]++
+		s.last = entry
+	}
+	keys := make([]string, 0, len(byAddress))
+	for key := range byAddress {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "地址\t签名次数\t用途\t首次签名\t最近签名")
+	for _, key := range keys {
+		s := byAddress[key]
+		purposes := make([]string, 0, len(s.purposes))
+		for purpose, n := range s.purposes {
+			purposes = append(purposes, fmt.Sprintf("%s×%d", valueOrDash(purpose), n))
+		}
+		sort.Strings(purposes)
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\n", s.address, s.count, strings.Join(purposes, ","),
+			s.first.Time.Local().Format(auditTimeLayout), s.last.Time.Local().Format(auditTimeLayout))
+	}
+	return tw.Flush()
+}
+
+// printAuditEntries 列出某个地址的全部签名记录
+func printAuditEntries(w io.Writer, entries []signer.AuditEntry, address string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "序号\t时间\t用途\t域名\tURI\t链ID\tNonce\t消息哈希")
+	found := 0
+	for _, entry := range entries {
+		if !strings.EqualFold(entry.Address, address) {
+			continue
+		}
+		found++
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", entry.Seq, entry.Time.Local().Format(auditTimeLayout),
+			valueOrDash(entry.Purpose), entry.Domain, entry.URI, entry.ChainID, entry.Nonce, entry.MessageHash)
+	}
+	if found == 0 {
+		return fmt.Errorf("签名审计日志中没有地址 %s 的记录", address)
+	}
+	return tw.Flush()
+}
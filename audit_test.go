@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"blockmesh/signer"
+)
+
+func TestAuditVerifyCommand(t *testing.T) {
+	configPath, dir := writeTestConfig(t, "http://127.0.0.1:1", 1, nil)
+	auditFile := filepath.Join(dir, "signatures.jsonl")
+	log := signer.NewAuditLog(auditFile)
+	for _, nonce := range []string{"nonce1", "nonce2", "nonce3"} {
+		if err := log.Append(signer.AuditEntry{Address: "0xabc", Nonce: nonce, Purpose: signer.PurposeLogin}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+	args := []string{"-config", configPath, "verify"}
+	if err := runAuditCommand(ctx, args); err != nil {
+		t.Fatalf("未修改的审计日志应校验通过: %v", err)
+	}
+
+	data, err := os.ReadFile(auditFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(auditFile, bytes.Replace(data, []byte("nonce2"), []byte("nonceX"), 1), 0600); err != nil {
+		t.Fatal(err)
+	}
+	err = runAuditCommand(ctx, args)
+	if err == nil {
+		t.Fatalf("被修改的审计日志应校验失败")
+	}
+	if code := exitCode(ctx, err); code != exitFailure {
+		t.Errorf("退出码为 %d，期望 %d", code, exitFailure)
+	}
+
+	if err := runAuditCommand(ctx, []string{"-config", configPath, "rewrite"}); exitCode(ctx, err) != exitUsage {
+		t.Errorf("未知操作应以 %d 退出，实际 %v", exitUsage, err)
+	}
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"blockmesh/config"
+	"blockmesh/internal/httpclient"
+	"blockmesh/internal/logging"
+	"blockmesh/runner"
+)
+
+// Cassette 一次请求与响应的录制内容，敏感信息已隐藏
+type Cassette struct {
+	Address    string           `json:"address"`
+	Stage      string           `json:"stage"`
+	RecordedAt time.Time        `json:"recorded_at"`
+	Request    CassetteRequest  `json:"request"`
+	Response   CassetteResponse `json:"response"`
+}
+
+// CassetteRequest 录制的请求
+type CassetteRequest struct {
+	Method string              `json:"method"`
+	URL    string              `json:"url"`
+	Header map[string][]string `json:"header"`
+	Body   json.RawMessage     `json:"body,omitempty"`
+	Text   string              `json:"text,omitempty"`
+}
+
+// CassetteResponse 录制的响应
+type CassetteResponse struct {
+	StatusCode int                 `json:"status_code"`
+	Header     map[string][]string `json:"header"`
+	Body       json.RawMessage     `json:"body,omitempty"`
+	Text       string              `json:"text,omitempty"`
+}
+
+// 响应中需要隐藏的 Token 字段
+var redactedResponseFields = map[string]bool{
+	"token":              true,
+	"privy_access_token": true,
+	"refresh_token":      true,
+	"identity_token":     true,
+	"userLogin":          true,
+}
+
+// cassetteRecorder 录制与回放的公共状态
+type cassetteRecorder struct {
+	mu   sync.Mutex
+	dir  string
+	seqs map[string]int
+}
+
+// nextPath 返回 <目录>/<地址>/<阶段>-<序号>.json，同一阶段多次请求按顺序编号
+func (c *cassetteRecorder) nextPath(ctx context.Context) (string, string, string) {
+	address, stage := httpclient.Labels(ctx)
+	key := address + "/" + stage
+	c.mu.Lock()
+	c.seqs[key]++
+	seq := c.seqs[key]
+	c.mu.Unlock()
+	name := fmt.Sprintf("%s-%03d.json", sanitizeFileName(stage), seq)
+	return filepath.Join(c.dir, sanitizeFileName(address), name), address, stage
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func sanitizeFileName(name string) string {
+	return unsafeFileChars.ReplaceAllString(name, "_")
+}
+
+// recordingTransport 正常发送请求，并把请求与响应写入录制目录
+type recordingTransport struct {
+	*cassetteRecorder
+	base http.RoundTripper
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			reqBody, _ = io.ReadAll(body)
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	plainBody := respBody
+	header := resp.Header.Clone()
+	if strings.EqualFold(header.Get("Content-Encoding"), "gzip") {
+		if reader, err := gzip.NewReader(bytes.NewReader(respBody)); err == nil {
+			if decoded, err := io.ReadAll(reader); err == nil {
+				plainBody = decoded
+				header.Del("Content-Encoding")
+				header.Del("Content-Length")
+			}
+		}
+	}
+
+	path, address, stage := t.nextPath(req.Context())
+	cassette := Cassette{
+		Address:    address,
+		Stage:      stage,
+		RecordedAt: time.Now(),
+		Request: CassetteRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: redactHeader(req.Header),
+		},
+		Response: CassetteResponse{
+			StatusCode: resp.StatusCode,
+			Header:     redactHeader(header),
+		},
+	}
+	cassette.Request.Body, cassette.Request.Text = redactBody(reqBody, redactedFields)
+	cassette.Response.Body, cassette.Response.Text = redactBody(plainBody, redactedResponseFields)
+	if err := writeCassette(path, &cassette); err != nil {
+		logging.Warning("写入录制文件失败: %v", err)
+	}
+	return resp, nil
+}
+
+// replayTransport 从录制目录读取响应，不访问网络
+type replayTransport struct {
+	*cassetteRecorder
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	path, _, _ := t.nextPath(req.Context())
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("回放失败，找不到录制文件 %s: %v", path, err)
+	}
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("解析录制文件 %s 失败: %v", path, err)
+	}
+
+	body := []byte(cassette.Response.Body)
+	if len(body) == 0 {
+		body = []byte(cassette.Response.Text)
+	}
+	// 录制时的 Date 头会被误认为本地时钟偏差
+	header := http.Header(cassette.Response.Header)
+	header.Del("Date")
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", cassette.Response.StatusCode, http.StatusText(cassette.Response.StatusCode)),
+		StatusCode:    cassette.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// recordingWrapper 返回录制模式的传输层包装，请求照常发送，响应写入 dir
+func recordingWrapper(dir string) httpclient.Wrapper {
+	recorder := &cassetteRecorder{dir: dir, seqs: make(map[string]int)}
+	return func(base http.RoundTripper) http.RoundTripper {
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		return &recordingTransport{cassetteRecorder: recorder, base: base}
+	}
+}
+
+// replayWrapper 返回回放模式的传输层包装，从 dir 读取响应，不访问网络
+func replayWrapper(dir string) httpclient.Wrapper {
+	replayer := &replayTransport{cassetteRecorder: &cassetteRecorder{dir: dir, seqs: make(map[string]int)}}
+	return func(http.RoundTripper) http.RoundTripper {
+		return replayer
+	}
+}
+
+// replayConfig 返回回放用的配置副本，回放不能修改真实数据：状态、奖励账本、会话缓存与签名审计日志写入临时目录，
+// 并且不发送通知。状态复制一份到临时目录，-failed-last-run 等选择条件仍按真实状态计算。返回的函数删除临时目录
+func replayConfig(cfg *config.Config) (*config.Config, func(), error) {
+	state, err := runner.LoadState(cfg.StateFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取状态失败: %v", err)
+	}
+	dir, err := os.MkdirTemp("", "coinshift-replay-")
+	if err != nil {
+		return nil, nil, fmt.Errorf("创建回放临时目录失败: %v", err)
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	replay := *cfg
+	replay.StateFile = filepath.Join(dir, "state.json")
+	replay.LedgerFile = filepath.Join(dir, "rewards.jsonl")
+	replay.SessionFile = filepath.Join(dir, "sessions.json")
+	replay.AuditFile = filepath.Join(dir, "signatures.jsonl")
+	replay.Notifiers = nil
+
+	data, err := json.Marshal(state)
+	if err == nil {
+		err = os.WriteFile(replay.StateFile, data, 0600)
+	}
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("复制状态失败: %v", err)
+	}
+	return &replay, cleanup, nil
+}
+
+func writeCassette(path string, cassette *Cassette) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// redactHeader 复制请求头并隐藏敏感信息
+func redactHeader(header http.Header) map[string][]string {
+	out := make(map[string][]string, len(header))
+	for key, values := range header {
+		if redactedHeaders[http.CanonicalHeaderKey(key)] || strings.EqualFold(key, "Cookie") || strings.EqualFold(key, "Set-Cookie") {
+			out[key] = []string{"<redacted>"}
+			continue
+		}
+		out[key] = append([]string(nil), values...)
+	}
+	return out
+}
+
+// redactBody 隐藏 JSON 中的敏感字段，非 JSON 内容按原文保存
+func redactBody(data []byte, fields map[string]bool) (json.RawMessage, string) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, ""
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, string(data)
+	}
+	redactFields(v, fields)
+	out, err := json.Marshal(v)
+	if err != nil {
+		return nil, string(data)
+	}
+	return out, ""
+}
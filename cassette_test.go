@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"blockmesh/fakeserver"
+)
+
+func TestReplayHasNoSideEffects(t *testing.T) {
+	server, ts := newTestServer(t, fakeserver.Options{})
+	var notified atomic.Int32
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		notified.Add(1)
+	}))
+	defer webhook.Close()
+
+	configPath, dir := writeTestConfig(t, ts.URL, 1, map[string]interface{}{
+		"notifiers": []map[string]string{{"type": "webhook", "url": webhook.URL}},
+	})
+	cassettes := filepath.Join(dir, "cassettes")
+	ctx := context.Background()
+
+	if err := runCommand(ctx, []string{"-config", configPath, "-record", cassettes}); err != nil {
+		t.Fatalf("录制失败: %v", err)
+	}
+	if notified.Load() != 1 {
+		t.Fatalf("录制时应发送 1 次通知，实际 %d 次", notified.Load())
+	}
+	verifyCalls := server.Calls("VerifyActivity")
+
+	files := []string{"state.json", "rewards.jsonl", "sessions.json", "signatures.jsonl"}
+	before := make(map[string][]byte)
+	for _, name := range files {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("录制后缺少 %s: %v", name, err)
+		}
+		before[name] = data
+	}
+	// 删除会话缓存，回放时需要完整走一遍登录流程
+	os.Remove(filepath.Join(dir, "sessions.json"))
+	delete(before, "sessions.json")
+	ts.Close()
+
+	if err := runCommand(ctx, []string{"-config", configPath, "-replay", cassettes}); err != nil {
+		t.Fatalf("回放失败: %v", err)
+	}
+	for name, data := range before {
+		after, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(after) != string(data) {
+			t.Errorf("回放修改了 %s", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "sessions.json")); !os.IsNotExist(err) {
+		t.Errorf("回放写入了会话缓存")
+	}
+	if notified.Load() != 1 {
+		t.Errorf("回放发送了通知")
+	}
+	if server.Calls("VerifyActivity") != verifyCalls {
+		t.Errorf("回放访问了假服务")
+	}
+}
@@ -0,0 +1,798 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"blockmesh/config"
+	"blockmesh/fakeserver"
+	"blockmesh/internal/httpclient"
+	"blockmesh/internal/logging"
+	"blockmesh/internal/runlock"
+	"blockmesh/runner"
+	"blockmesh/signer"
+)
+
+// 退出码
+const (
+	exitOK          = 0   // 全部账户执行成功
+	exitFailure     = 1   // 全部账户执行失败或执行出错
+	exitUsage       = 2   // 参数错误
+	exitPartial     = 3   // 部分账户执行失败
+	exitLocked      = 4   // 已有实例在运行，未获取到运行锁
+	exitConfig      = 5   // 配置文件有误
+	exitInterrupted = 130 // 收到 Ctrl+C 或 SIGTERM 后中断
+)
+
+// usageError 参数错误，以 exitUsage 退出
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string { return e.err.Error() }
+
+func usageErrorf(format string, v ...interface{}) error {
+	return &usageError{err: fmt.Errorf(format, v...)}
+}
+
+// configError 配置文件有误，以 exitConfig 退出。
+// 读取文件、口令错误与密钥来源读取失败不属于配置错误，以 exitFailure 退出
+type configError struct {
+	err error
+}
+
+func (e *configError) Error() string { return e.err.Error() }
+
+// loadError 包装加载配置时的错误，配置本身有误时返回 *configError
+func loadError(err error) error {
+	var problems *config.Error
+	if errors.As(err, &problems) {
+		return &configError{err: fmt.Errorf("加载配置失败: %v", err)}
+	}
+	return fmt.Errorf("加载配置失败: %v", err)
+}
+
+// 执行结果错误，错误信息已输出到日志
+var (
+	errRunFailed   = errors.New("全部账户执行失败")
+	errRunPartial  = errors.New("部分账户执行失败")
+	errInterrupted = errors.New("执行被中断")
+)
+
+// command 子命令
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+// commands 全部子命令，未指定子命令时执行 run
+var commands = []command{
+	{"run", "执行全部账户的每日签到 (默认)", runCommand},
+	{"daemon", "常驻运行，通过本地管理 API 触发执行", runDaemonCommand},
+	{"addresses", "列出每个账户派生的地址", runAddressesCommand},
+	{"validate", "检查配置文件", runValidateCommand},
+	{"status", "查询积分与排名，不领取奖励", runStatusCommand},
+	{"discover", "列出活动下的全部任务", runDiscoverCommand},
+	{"missions", "查看活动与任务进度", runMissionsCommand},
+	{"rewards", "查看奖励账本汇总", runRewardsCommand},
+	{"sessions", "查看或清除缓存的登录会话 (list|clear)", runSessionsCommand},
+	{"audit", "校验签名审计日志或按地址列出签名 (verify|list)", runAuditCommand},
+	{"encrypt-config", "加密配置文件", runEncryptConfigCommand},
+	{"decrypt-config", "解密配置文件并输出到标准输出", runDecryptConfigCommand},
+	{"fakeserver", "启动本地 Privy 与 Deform 假服务", runFakeServerCommand},
+}
+
+// runCLI 解析子命令并执行，返回进程退出码
+func runCLI(args []string) int {
+	name := "run"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		printUsage(os.Stderr)
+		return exitOK
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		err := cmd.run(ctx, args)
+		switch {
+		case err == nil, errors.Is(err, flag.ErrHelp):
+		case errors.Is(err, errRunFailed), errors.Is(err, errRunPartial), errors.Is(err, errInterrupted):
+			// 结果已输出到日志
+		default:
+			logging.Error("%v", err)
+		}
+		return exitCode(ctx, err)
+	}
+
+	logging.Error("未知的子命令: %s", name)
+	printUsage(os.Stderr)
+	return exitUsage
+}
+
+// exitCode 返回错误对应的退出码，ctx 已取消时视为被中断
+func exitCode(ctx context.Context, err error) int {
+	var usageErr *usageError
+	var configErr *configError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.As(err, &usageErr):
+		return exitUsage
+	case errors.As(err, &configErr):
+		return exitConfig
+	case errors.Is(err, errRunPartial):
+		return exitPartial
+	case runlock.IsHeld(err):
+		return exitLocked
+	case errors.Is(err, errInterrupted), ctx.Err() != nil:
+		return exitInterrupted
+	default:
+		return exitFailure
+	}
+}
+
+// runOutcome 根据账户执行结果返回对应的错误，全部成功时返回 nil
+func runOutcome(ctx context.Context, results []*runner.AccountResult) error {
+	failed := 0
+	for _, result := range results {
+		if !result.Success {
+			failed++
+		}
+	}
+	return outcome(ctx, failed, len(results))
+}
+
+// outcome 根据失败账户数返回执行结果错误
+func outcome(ctx context.Context, failed, total int) error {
+	switch {
+	case ctx.Err() != nil:
+		return errInterrupted
+	case failed == 0:
+		return nil
+	case failed == total:
+		return errRunFailed
+	default:
+		return errRunPartial
+	}
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "用法: %s <子命令> [参数]\n\n子命令:\n", os.Args[0])
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(tw, "  %s\t%s\n", cmd.name, cmd.summary)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\n使用 %s <子命令> -h 查看子命令参数\n", os.Args[0])
+}
+
+// commonFlags 所有子命令共用的 -config 与 -passphrase 参数
+type commonFlags struct {
+	config     string
+	passphrase string
+}
+
+// loadOptions 根据命令行参数生成配置加载选项
+func (f *commonFlags) loadOptions() config.LoadOptions {
+	return config.LoadOptions{PassphraseRef: f.passphrase}
+}
+
+// newFlagSet 创建子命令参数集，所有子命令共用 -config 与 -passphrase
+func newFlagSet(name string) (*flag.FlagSet, *commonFlags) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	common := &commonFlags{}
+	fs.StringVar(&common.config, "config", "config.json", "配置文件路径")
+	fs.StringVar(&common.passphrase, "passphrase", "", "加密配置的口令来源，例如 env:MY_PASS、file:/path、cmd:pass show coinshift (默认读取 "+config.PassphraseEnv+" 或终端输入)")
+	return fs, common
+}
+
+// parseFlags 解析参数，参数错误以 exitUsage 退出
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return &usageError{err: err}
+	}
+	return nil
+}
+
+// loadCommandConfig 加载配置文件
+func loadCommandConfig(common *commonFlags) (*config.Config, error) {
+	cfg, err := config.Load(common.config, common.loadOptions())
+	if err != nil {
+		return nil, loadError(err)
+	}
+	logging.Success("成功加载配置文件，共 %d 个账户 ", len(cfg.Accounts))
+	return cfg, nil
+}
+
+// acquireRunLock 获取运行锁，已有实例在运行时最多等待 wait
+func acquireRunLock(ctx context.Context, cfg *config.Config, command string, wait time.Duration) (*runlock.Lock, error) {
+	lock, err := runlock.Acquire(cfg.LockFile, command)
+	var held *runlock.HeldError
+	if errors.As(err, &held) && wait > 0 {
+		logging.Info("已有实例在运行 (%s)，最多等待 %s", held.Holder, wait)
+		lock, err = runlock.Wait(ctx, cfg.LockFile, command, wait)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if stale := lock.Stale(); stale != nil {
+		logging.Warning("已清除过期的运行锁 (%s)", stale)
+	}
+	return lock, nil
+}
+
+// releaseRunLock 释放运行锁
+func releaseRunLock(lock *runlock.Lock) {
+	if err := lock.Release(); err != nil {
+		logging.Error("%v", err)
+	}
+}
+
+func printBanner() {
+	logging.Start("     Coinshift 每日签到脚本")
+	logging.Start("欢迎关注「闲菜」矩阵账号获取深度内容：")
+	logging.Start("公众号搜索：「闲菜web3日记」、「加密之友闲菜哥」、「闲菜解码WEB3」")
+	logging.Start("视频号、YouTube：「加密小闲菜」")
+	logging.Start("Twitter：「@xiancai4188391」\n")
+}
+
+// runCommand 执行全部账户的每日签到
+func runCommand(ctx context.Context, args []string) error {
+	fs, common := newFlagSet("run")
+	dryRun := fs.Bool("dry-run", false, "预演模式：只生成签名并打印将要发送的请求，不发送任何请求")
+	dryRunVerify := fs.Bool("dry-run-verify", false, "预演模式下在本地校验签名")
+	dryRunFetchNonce := fs.Bool("dry-run-fetch-nonce", false, "预演模式下允许调用只读的 Privy 初始化接口获取真实 Nonce")
+	recordDir := fs.String("record", "", "录制模式：把请求与响应 (已隐藏敏感信息) 按账户和阶段保存到该目录")
+	replayDir := fs.String("replay", "", "回放模式：从该目录读取录制的响应代替网络请求")
+	waitLock := fs.Duration("wait-lock", 0, "已有实例在运行时最多等待的时长，例如 10m，0 表示不等待直接退出")
+	output := fs.String("output", outputText, "结果输出格式：text 或 ndjson (每个账户与活动一行 JSON 输出到标准输出，日志输出到标准错误)")
+	var selector runner.AccountSelector
+	fs.Var((*listFlag)(&selector.Only), "only", "只执行指定账户，可填序号、地址或标签，逗号分隔或重复使用")
+	fs.Var((*listFlag)(&selector.Skip), "skip", "跳过指定账户，可填序号、地址或标签，逗号分隔或重复使用")
+	fs.IntVar(&selector.From, "from", 0, "从第 N 个账户开始执行 (包含)")
+	fs.IntVar(&selector.To, "to", 0, "执行到第 N 个账户为止 (包含)")
+	fs.Var((*listFlag)(&selector.Tags), "tag", "只执行带有任一标签的账户，逗号分隔或重复使用")
+	fs.BoolVar(&selector.FailedLastRun, "failed-last-run", false, "只执行上次执行失败的账户 (读取状态文件)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	switch *output {
+	case outputText, outputNDJSON:
+	default:
+		return usageErrorf("未知的输出格式: %s (可选 text、ndjson)", *output)
+	}
+	if *output == outputNDJSON && *dryRun {
+		return usageErrorf("-output ndjson 不能与 -dry-run 同时使用")
+	}
+	printBanner()
+
+	var wrapper httpclient.Wrapper
+	switch {
+	case *recordDir != "" && *replayDir != "":
+		return usageErrorf("-record 与 -replay 不能同时使用")
+	case *recordDir != "":
+		wrapper = recordingWrapper(*recordDir)
+		logging.Info("录制模式已开启，录制文件保存到 %s", *recordDir)
+	case *replayDir != "":
+		wrapper = replayWrapper(*replayDir)
+		logging.Info("回放模式已开启，从 %s 读取录制文件", *replayDir)
+	}
+
+	cfg, err := loadCommandConfig(common)
+	if err != nil {
+		return err
+	}
+	lock, err := acquireRunLock(ctx, cfg, "run", *waitLock)
+	if err != nil {
+		return err
+	}
+	defer releaseRunLock(lock)
+	if *replayDir != "" {
+		replay, cleanup, err := replayConfig(cfg)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		cfg = replay
+		logging.Info("回放模式不会修改状态、奖励账本与会话缓存，也不会发送通知")
+	}
+
+	r, err := runner.New(common.config, cfg)
+	if err != nil {
+		return fmt.Errorf("初始化失败: %v", err)
+	}
+	r.SetTransportWrapper(wrapper)
+	if *replayDir != "" {
+		// 回放中的签名不会发送给任何服务，不写入签名审计日志
+		r.SetAuditLog(nil)
+	}
+	filter, err := selector.Filter(r.State())
+	if err != nil {
+		return &usageError{err: err}
+	}
+	selected := runner.SelectedAccounts(cfg.Accounts, filter)
+	if selected == 0 {
+		return usageErrorf("没有符合选择条件的账户")
+	}
+	if filter != nil {
+		logging.Info("已选择 %d/%d 个账户", selected, len(cfg.Accounts))
+	}
+
+	if *dryRun {
+		opts := DryRunOptions{VerifySignatures: *dryRunVerify, FetchNonce: *dryRunFetchNonce}
+		if err := runDryRun(ctx, cfg, r.ClientOptions(), filter, opts, os.Stdout); err != nil {
+			return fmt.Errorf("预演失败: %v", err)
+		}
+		return nil
+	}
+
+	var stream *resultStream
+	if *output == outputNDJSON {
+		stream = newResultStream(os.Stdout)
+		r.OnResult(func(result *runner.AccountResult) {
+			if err := stream.WriteAccount(result); err != nil {
+				logging.Error("%v", err)
+			}
+		})
+	}
+
+	results, err := r.Run(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("执行失败: %v", err)
+	}
+	outcome := runOutcome(ctx, results)
+	if stream != nil {
+		if err := stream.WriteSummary(results, errors.Is(outcome, errInterrupted), exitCode(ctx, outcome)); err != nil {
+			logging.Error("%v", err)
+		}
+	}
+	switch {
+	case outcome == nil:
+		logging.Success("所有账户处理完成")
+	case errors.Is(outcome, errInterrupted):
+		logging.Warning("执行被中断，已处理 %d/%d 个账户", len(results), selected)
+	default:
+		logging.Error("%v", outcome)
+	}
+	return outcome
+}
+
+// runDaemonCommand 常驻运行本地管理 API
+func runDaemonCommand(ctx context.Context, args []string) error {
+	fs, common := newFlagSet("daemon")
+	apiListen := fs.String("api", "", "本地管理 API 的监听地址，例如 127.0.0.1:8686 (覆盖配置文件)")
+	watchInterval := fs.Duration("watch-interval", runner.DefaultWatchInterval, "检查配置文件是否修改的间隔，0 表示只在收到 SIGHUP 时重新加载")
+	waitLock := fs.Duration("wait-lock", 0, "已有实例在运行时最多等待的时长，例如 10m，0 表示不等待直接退出")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	printBanner()
+
+	cfg, err := loadCommandConfig(common)
+	if err != nil {
+		return err
+	}
+	if *apiListen != "" {
+		cfg.API.Listen = *apiListen
+	}
+	if cfg.API.Listen == "" {
+		return usageErrorf("未配置 api.listen，请在配置文件中设置或使用 -api 参数")
+	}
+	lock, err := acquireRunLock(ctx, cfg, "daemon", *waitLock)
+	if err != nil {
+		return err
+	}
+	defer releaseRunLock(lock)
+
+	r, err := runner.New(common.config, cfg)
+	if err != nil {
+		return fmt.Errorf("初始化失败: %v", err)
+	}
+	go r.Watch(ctx, *watchInterval)
+	if err := serveAPI(ctx, r, cfg.API); err != nil {
+		return fmt.Errorf("管理 API 异常退出: %v", err)
+	}
+	return nil
+}
+
+// runAddressesCommand 输出每个账户的地址，不发送任何请求
+func runAddressesCommand(ctx context.Context, args []string) error {
+	fs, common := newFlagSet("addresses")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	cfg, err := loadCommandConfig(common)
+	if err != nil {
+		return err
+	}
+
+	failed := false
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\t标签\t地址\t代理")
+	for i, account := range cfg.Accounts {
+		address, err := signer.GetAddressFromPrivateKey(account.PrivateKey)
+		if err != nil {
+			logging.Error("账户 #%d 获取地址失败: %v", i+1, err)
+			address, failed = "-", true
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", i+1, valueOrDash(account.Display()), address, valueOrDash(runner.RedactProxy(account.Proxy)))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if failed {
+		return usageErrorf("部分账户私钥无效")
+	}
+	return nil
+}
+
+// runValidateCommand 检查配置文件，有问题时以 exitConfig 退出
+func runValidateCommand(ctx context.Context, args []string) error {
+	fs, common := newFlagSet("validate")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	cfg, err := config.Load(common.config, common.loadOptions())
+	var configErr *config.Error
+	if errors.As(err, &configErr) {
+		for _, problem := range configErr.Problems {
+			fmt.Fprintln(os.Stdout, problem)
+		}
+		return &configError{err: fmt.Errorf("配置文件存在 %d 个问题", len(configErr.Problems))}
+	}
+	if err != nil {
+		return loadError(err)
+	}
+	fmt.Fprintf(os.Stdout, "配置文件检查通过，共 %d 个账户、%d 个活动\n", len(cfg.Accounts), len(cfg.Campaign.EnabledActivities()))
+	return nil
+}
+
+// runStatusCommand 查询积分与排名
+func runStatusCommand(ctx context.Context, args []string) error {
+	fs, common := newFlagSet("status")
+	waitLock := fs.Duration("wait-lock", 0, "已有实例在运行时最多等待的时长，例如 10m，0 表示不等待直接退出")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	cfg, err := loadCommandConfig(common)
+	if err != nil {
+		return err
+	}
+	if cfg.Campaign.ID == "" {
+		return usageErrorf("未配置 campaign.id，无法查询积分与排名")
+	}
+	// 查询前可能重新登录，会写入会话缓存与签名审计日志
+	lock, err := acquireRunLock(ctx, cfg, "status", *waitLock)
+	if err != nil {
+		return err
+	}
+	defer releaseRunLock(lock)
+	sessions, err := runner.LoadSessions(cfg.SessionFile, cfg.EncryptionPassphrase())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := sessions.Save(); err != nil {
+			logging.Error("保存会话缓存失败: %v", err)
+		}
+	}()
+	return runStatus(ctx, cfg, runner.DefaultProviders(cfg.Endpoints, runner.NewClientOptions(cfg)), sessions, os.Stdout)
+}
+
+// runDiscoverCommand 列出活动下的全部任务
+func runDiscoverCommand(ctx context.Context, args []string) error {
+	fs, common := newFlagSet("discover")
+	campaignID := fs.String("campaign", "", "活动ID (覆盖配置文件中的 campaign.id)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	cfg, err := loadCommandConfig(common)
+	if err != nil {
+		return err
+	}
+	if *campaignID != "" {
+		cfg.Campaign.ID = *campaignID
+	}
+	if cfg.Campaign.ID == "" {
+		return usageErrorf("未配置 campaign.id，请在配置文件中设置或使用 -campaign 参数")
+	}
+	if err := runDiscover(ctx, cfg, runner.DefaultProviders(cfg.Endpoints, runner.NewClientOptions(cfg)).Backend, os.Stdout); err != nil {
+		return fmt.Errorf("查询活动列表失败: %v", err)
+	}
+	return nil
+}
+
+// runMissionsCommand 输出活动与任务进度
+func runMissionsCommand(ctx context.Context, args []string) error {
+	fs, common := newFlagSet("missions")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	cfg, err := loadCommandConfig(common)
+	if err != nil {
+		return err
+	}
+	state, err := runner.LoadState(cfg.StateFile)
+	if err != nil {
+		return fmt.Errorf("读取状态失败: %v", err)
+	}
+	if err := printMissionProgress(os.Stdout, cfg, state); err != nil {
+		return fmt.Errorf("输出任务进度失败: %v", err)
+	}
+	return nil
+}
+
+// runRewardsCommand 输出奖励账本汇总
+func runRewardsCommand(ctx context.Context, args []string) error {
+	fs, common := newFlagSet("rewards")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	cfg, err := loadCommandConfig(common)
+	if err != nil {
+		return err
+	}
+	ledger, err := runner.OpenLedger(cfg.LedgerFile)
+	if err != nil {
+		return fmt.Errorf("打开奖励账本失败: %v", err)
+	}
+	entries, err := ledger.Entries()
+	if err != nil {
+		return fmt.Errorf("读取奖励账本失败: %v", err)
+	}
+	labels := make(map[string]string)
+	for _, account := range cfg.Accounts {
+		if address, err := signer.GetAddressFromPrivateKey(account.PrivateKey); err == nil {
+			labels[strings.ToLower(address)] = account.Display()
+		}
+	}
+	printRewardReport(os.Stdout, entries, labels)
+	return nil
+}
+
+// runSessionsCommand 查看或清除缓存的登录会话
+func runSessionsCommand(ctx context.Context, args []string) error {
+	fs, common := newFlagSet("sessions")
+	address := fs.String("address", "", "clear 时只清除该地址的会话")
+	waitLock := fs.Duration("wait-lock", 0, "clear 时已有实例在运行最多等待的时长，0 表示不等待直接退出")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	action := "list"
+	if fs.NArg() > 0 {
+		action = fs.Arg(0)
+		// 允许参数写在动作之后，例如 sessions clear -address 0x...
+		if err := parseFlags(fs, fs.Args()[1:]); err != nil {
+			return err
+		}
+	}
+	if fs.NArg() > 0 {
+		return usageErrorf("多余的参数: %s", strings.Join(fs.Args(), " "))
+	}
+
+	cfg, err := loadCommandConfig(common)
+	if err != nil {
+		return err
+	}
+	// 清除会话会改写会话缓存，需要与 run、daemon 互斥，否则会被正在执行的实例覆盖
+	if action == "clear" {
+		lock, err := acquireRunLock(ctx, cfg, "sessions clear", *waitLock)
+		if err != nil {
+			return err
+		}
+		defer releaseRunLock(lock)
+	}
+	sessions, err := runner.LoadSessions(cfg.SessionFile, cfg.EncryptionPassphrase())
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "list":
+		now := time.Now()
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "地址\t登录时间\t过期时间\t状态")
+		for _, session := range sessions.List() {
+			state := "有效"
+			if !session.Valid(now) {
+				state = "已过期"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", session.Address,
+				session.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+				session.ExpiresAt.Local().Format("2006-01-02 15:04:05"), state)
+		}
+		return tw.Flush()
+	case "clear":
+		if *address != "" {
+			if !sessions.Delete(*address) {
+				return fmt.Errorf("没有地址 %s 的会话", *address)
+			}
+			logging.Success("已清除 %s 的会话", *address)
+		} else {
+			logging.Success("已清除 %d 个会话", sessions.Clear())
+		}
+		return sessions.Save()
+	default:
+		return usageErrorf("未知的 sessions 操作: %s (可选 list、clear)", action)
+	}
+}
+
+// runAuditCommand 校验签名审计日志的哈希链，或按地址列出签名记录
+func runAuditCommand(ctx context.Context, args []string) error {
+	fs, common := newFlagSet("audit")
+	address := fs.String("address", "", "list 时只列出该地址的签名记录")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	action := "list"
+	if fs.NArg() > 0 {
+		action = fs.Arg(0)
+		// 允许参数写在动作之后，例如 audit list -address 0x...
+		if err := parseFlags(fs, fs.Args()[1:]); err != nil {
+			return err
+		}
+	}
+	if fs.NArg() > 0 {
+		return usageErrorf("多余的参数: %s", strings.Join(fs.Args(), " "))
+	}
+
+	cfg, err := loadCommandConfig(common)
+	if err != nil {
+		return err
+	}
+	auditLog := signer.NewAuditLog(cfg.AuditFile)
+
+	switch action {
+	case "verify":
+		n, err := auditLog.Verify()
+		if err != nil {
+			return fmt.Errorf("签名审计日志 %s 校验失败 (前 %d 条记录完好): %v", auditLog.Path(), n, err)
+		}
+		logging.Success("签名审计日志 %s 校验通过，共 %d 条记录", auditLog.Path(), n)
+		return nil
+	case "list":
+		entries, err := auditLog.Entries()
+		if err != nil {
+			return err
+		}
+		if *address != "" {
+			return printAuditEntries(os.Stdout, entries, *address)
+		}
+		return printAuditSummary(os.Stdout, entries)
+	default:
+		return usageErrorf("未知的 audit 操作: %s (可选 verify、list)", action)
+	}
+}
+
+// runEncryptConfigCommand 加密配置文件，输出到 -out 指定的文件
+func runEncryptConfigCommand(ctx context.Context, args []string) error {
+	fs, common := newFlagSet("encrypt-config")
+	out := fs.String("out", "", "加密后的文件路径，默认为 <配置文件>.enc")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *out == "" {
+		*out = common.config + ".enc"
+	}
+
+	plaintext, err := os.ReadFile(common.config)
+	if err != nil {
+		return fmt.Errorf("读取配置文件失败: %v", err)
+	}
+	defer clear(plaintext)
+	if _, ok := config.ParseEncrypted(plaintext); ok {
+		return usageErrorf("%s 已经是加密的配置文件", common.config)
+	}
+	format := config.Format(common.config)
+	if _, err := config.Parse(format, plaintext); err != nil {
+		return &configError{err: fmt.Errorf("解析配置文件失败: %v", err)}
+	}
+
+	passphrase, err := common.loadOptions().NewPassphrase()
+	if err != nil {
+		return err
+	}
+	data, err := config.Encrypt(plaintext, format, passphrase)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*out, data, 0600); err != nil {
+		return fmt.Errorf("写入加密配置失败: %v", err)
+	}
+	logging.Success("已加密到 %s，确认可以正常加载后请删除明文配置 %s", *out, common.config)
+	return nil
+}
+
+// runDecryptConfigCommand 解密配置文件，明文只输出到标准输出，不写入磁盘
+func runDecryptConfigCommand(ctx context.Context, args []string) error {
+	fs, common := newFlagSet("decrypt-config")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(common.config)
+	if err != nil {
+		return fmt.Errorf("读取配置文件失败: %v", err)
+	}
+	envelope, ok := config.ParseEncrypted(data)
+	if !ok {
+		return usageErrorf("%s 不是加密的配置文件", common.config)
+	}
+	passphrase, err := common.loadOptions().Passphrase("请输入配置文件口令: ")
+	if err != nil {
+		return err
+	}
+	plaintext, err := envelope.Decrypt(passphrase)
+	if err != nil {
+		return err
+	}
+	defer clear(plaintext)
+	_, err = os.Stdout.Write(plaintext)
+	return err
+}
+
+// runFakeServerCommand 启动本地 Privy 与 Deform 假服务
+func runFakeServerCommand(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("fakeserver", flag.ContinueOnError)
+	listen := fs.String("listen", "127.0.0.1:8899", "监听地址")
+	behaviorFile := fs.String("behavior", "", "假服务行为配置文件 (JSON)，可配置活动状态与失败次数")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	var opts fakeserver.Options
+	if *behaviorFile != "" {
+		data, err := os.ReadFile(*behaviorFile)
+		if err != nil {
+			return fmt.Errorf("读取行为配置失败: %v", err)
+		}
+		if err := json.Unmarshal(data, &opts); err != nil {
+			return usageErrorf("解析行为配置失败: %v", err)
+		}
+	}
+
+	server := &http.Server{
+		Addr:              *listen,
+		Handler:           fakeserver.New(opts).Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		logging.Start("%s 假服务已启动，在配置文件中设置:", logging.IconNetwork)
+		logging.Start(`"endpoints": {"privy_base_url": "http://%s/api/v1", "deform_api_url": "http://%s/"}`, *listen, *listen)
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return fmt.Errorf("假服务异常退出: %v", err)
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"blockmesh/config"
+	"blockmesh/fakeserver"
+	"blockmesh/internal/runlock"
+)
+
+func TestCommandsRespectRunLock(t *testing.T) {
+	_, ts := newTestServer(t, fakeserver.Options{})
+	configPath, dir := writeTestConfig(t, ts.URL, 1, nil)
+	lock, err := runlock.Acquire(filepath.Join(dir, "coinshift.lock"), "daemon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	locked := map[string]func(context.Context, []string) error{
+		"run":            runCommand,
+		"status":         runStatusCommand,
+		"sessions clear": func(ctx context.Context, args []string) error { return runSessionsCommand(ctx, append(args, "clear")) },
+	}
+	for name, command := range locked {
+		if code := exitCode(ctx, command(ctx, []string{"-config", configPath})); code != exitLocked {
+			t.Errorf("%s: 锁被持有时退出码为 %d，期望 %d", name, code, exitLocked)
+		}
+	}
+	captureStdout(t, func() { err = runSessionsCommand(ctx, []string{"-config", configPath, "list"}) })
+	if err != nil {
+		t.Errorf("sessions list 只读取会话缓存，不需要运行锁: %v", err)
+	}
+
+	lock.Release()
+	captureStdout(t, func() { err = runStatusCommand(ctx, []string{"-config", configPath}) })
+	if err != nil {
+		t.Errorf("释放锁后 status 应成功: %v", err)
+	}
+	if err := runSessionsCommand(ctx, []string{"-config", configPath, "clear"}); err != nil {
+		t.Errorf("释放锁后 sessions clear 应成功: %v", err)
+	}
+}
+
+func TestConfigExitCodes(t *testing.T) {
+	ctx := context.Background()
+	unknownField, _ := writeTestConfig(t, "http://127.0.0.1:1", 1, map[string]interface{}{"unknown": true})
+	unconfigured, _ := writeTestConfig(t, "http://127.0.0.1:1", 1, map[string]interface{}{
+		"accounts": []map[string]string{{"private_key": "${http:key1}"}},
+	})
+	commandFails, _ := writeTestConfig(t, "http://127.0.0.1:1", 1, map[string]interface{}{
+		"accounts": []map[string]string{{"private_key": "${cmd:false}"}},
+	})
+
+	plain, dir := writeTestConfig(t, "http://127.0.0.1:1", 1, nil)
+	data, err := os.ReadFile(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := config.Encrypt(data, ".json", "right")
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryptedPath := filepath.Join(dir, "config.json.enc")
+	if err := os.WriteFile(encryptedPath, encrypted, 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_PASSPHRASE", "wrong")
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"未知字段", []string{"-config", unknownField}, exitConfig},
+		{"未配置的密钥来源", []string{"-config", unconfigured}, exitConfig},
+		{"密钥命令执行失败", []string{"-config", commandFails}, exitFailure},
+		{"口令错误", []string{"-config", encryptedPath, "-passphrase", "env:TEST_PASSPHRASE"}, exitFailure},
+		{"配置文件不存在", []string{"-config", filepath.Join(dir, "missing.json")}, exitFailure},
+		{"未知参数", []string{"-unknown"}, exitUsage},
+	}
+	for _, test := range tests {
+		for name, command := range map[string]func(context.Context, []string) error{"run": runCommand, "validate": runValidateCommand} {
+			var err error
+			captureStdout(t, func() { err = command(ctx, test.args) })
+			if code := exitCode(ctx, err); code != test.want {
+				t.Errorf("%s %s: 退出码为 %d，期望 %d (%v)", name, test.name, code, test.want, err)
+			}
+		}
+	}
+}
+
+func TestStatusExitCode(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		opts fakeserver.Options
+		want int
+	}{
+		{"全部成功", fakeserver.Options{}, exitOK},
+		{"部分失败", fakeserver.Options{FailLogin: 1}, exitPartial},
+		{"全部失败", fakeserver.Options{FailLogin: 2}, exitFailure},
+	}
+	for _, test := range tests {
+		_, ts := newTestServer(t, test.opts)
+		configPath, _ := writeTestConfig(t, ts.URL, 2, nil)
+		var err error
+		captureStdout(t, func() { err = runStatusCommand(ctx, []string{"-config", configPath}) })
+		if code := exitCode(ctx, err); code != test.want {
+			t.Errorf("%s: 退出码为 %d，期望 %d (%v)", test.name, code, test.want, err)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+// Package config 定义配置文件结构，负责加载 JSON、YAML、TOML (可加密) 配置、
+// 替换 ${...} 密钥引用并检查全部账户、活动与通知设置。
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Config 定义配置文件结构
+type Config struct {
+	Accounts    []AccountConfig  `json:"accounts"`
+	API         APIConfig        `json:"api,omitempty"`
+	Endpoints   EndpointsConfig  `json:"endpoints,omitempty"`
+	Campaign    CampaignConfig   `json:"campaign,omitempty"`
+	Notifiers   []NotifierConfig `json:"notifiers,omitempty"`
+	StateFile   string           `json:"state_file,omitempty"`
+	LedgerFile  string           `json:"ledger_file,omitempty"`
+	SessionFile string           `json:"session_file,omitempty"`
+	AuditFile   string           `json:"audit_file,omitempty"`
+	LockFile    string           `json:"lock_file,omitempty"`
+	Retries     *int             `json:"retries,omitempty"`
+	Secrets     SecretsConfig    `json:"secrets,omitempty"`
+	HTTP        HTTPConfig       `json:"http,omitempty"`
+	Clock       ClockConfig      `json:"clock,omitempty"`
+
+	// passphrase 加密配置文件的口令，未加密时为空
+	passphrase string
+	// options 加载配置时使用的选项，重新加载时复用
+	options LoadOptions
+}
+
+// EncryptionPassphrase 返回加密配置文件的口令，配置未加密时返回空字符串。
+// 会话缓存等保存 Token 的文件使用相同口令加密
+func (c *Config) EncryptionPassphrase() string {
+	return c.passphrase
+}
+
+// DefaultRetries 活动领取失败时的默认重试次数
+const DefaultRetries = 2
+
+// ActivityRetries 返回活动领取失败时的重试次数
+func (c *Config) ActivityRetries() int {
+	if c.Retries == nil {
+		return DefaultRetries
+	}
+	return *c.Retries
+}
+
+// EndpointsConfig 定义上游服务地址，便于指向本地假服务进行测试
+type EndpointsConfig struct {
+	PrivyBaseURL string `json:"privy_base_url,omitempty"`
+	DeformAPIURL string `json:"deform_api_url,omitempty"`
+}
+
+// HTTPConfig 定义访问 Privy 与 Deform 的请求超时
+type HTTPConfig struct {
+	// Timeout 默认请求超时 (秒)，默认 10 秒
+	Timeout int `json:"timeout,omitempty"`
+	// StageTimeouts 按阶段覆盖请求超时 (秒)，阶段名与录制文件相同，例如 verify-activity
+	StageTimeouts map[string]int `json:"stage_timeouts,omitempty"`
+}
+
+// TimeoutStages 可以单独设置超时的阶段，与 privy、deform 包中的 Stage* 常量一致
+var TimeoutStages = []string{
+	"privy-init",
+	"privy-authenticate",
+	"privy-refresh",
+	"deform-login",
+	"verify-activity",
+	"user-profile",
+	"campaign-activities",
+}
+
+// Timeouts 返回默认超时与按阶段覆盖的超时，未配置默认超时时返回 0
+func (h HTTPConfig) Timeouts() (time.Duration, map[string]time.Duration) {
+	stages := make(map[string]time.Duration, len(h.StageTimeouts))
+	for stage, seconds := range h.StageTimeouts {
+		stages[stage] = time.Duration(seconds) * time.Second
+	}
+	return time.Duration(h.Timeout) * time.Second, stages
+}
+
+// DefaultSkewWarning 本地时钟与服务器时间相差超过该值时告警
+const DefaultSkewWarning = 30 * time.Second
+
+// ClockConfig 定义本地时钟偏差检测，偏差根据 Privy 初始化响应的 Date 头计算
+type ClockConfig struct {
+	// SkewWarning 偏差超过该值 (秒) 时告警，默认 30 秒
+	SkewWarning int `json:"skew_warning,omitempty"`
+	// AlignToServer 按服务器时间校正 SIWE 的 Issued At 与 nonce 过期判断
+	AlignToServer bool `json:"align_to_server,omitempty"`
+}
+
+// SkewThreshold 返回告警阈值
+func (c ClockConfig) SkewThreshold() time.Duration {
+	if c.SkewWarning <= 0 {
+		return DefaultSkewWarning
+	}
+	return time.Duration(c.SkewWarning) * time.Second
+}
+
+// CampaignConfig 定义 Deform 活动配置
+type CampaignConfig struct {
+	ID         string           `json:"id,omitempty"`
+	Activities []ActivityConfig `json:"activities,omitempty"`
+}
+
+// ActivityConfig 定义需要领取的活动
+type ActivityConfig struct {
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Disabled bool   `json:"disabled,omitempty"`
+}
+
+// EnabledActivities 返回启用的活动列表，未配置时使用默认活动
+func (c CampaignConfig) EnabledActivities() []ActivityConfig {
+	if len(c.Activities) == 0 {
+		return DefaultActivities
+	}
+	var activities []ActivityConfig
+	for _, activity := range c.Activities {
+		if !activity.Disabled {
+			activities = append(activities, activity)
+		}
+	}
+	return activities
+}
+
+// ActivityName 返回活动名称，未配置名称时返回活动ID
+func (c CampaignConfig) ActivityName(activityID string) string {
+	for _, activity := range c.Activities {
+		if activity.ID == activityID && activity.Name != "" {
+			return activity.Name
+		}
+	}
+	return activityID
+}
+
+// AccountConfig 定义单个账户配置
+type AccountConfig struct {
+	PrivateKey   string   `json:"private_key"`
+	Proxy        string   `json:"proxy"`
+	RefreshToken string   `json:"refresh_token,omitempty"`
+	Label        string   `json:"label,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	// ExpectedAddress 私钥应当对应的地址，加载配置时校验，用于发现填错的私钥
+	ExpectedAddress string `json:"expected_address,omitempty"`
+}
+
+// Display 返回账户的标签与标签组，例如 "main [vps1,eu]"，都未设置时返回空字符串
+func (a AccountConfig) Display() string {
+	switch {
+	case len(a.Tags) == 0:
+		return a.Label
+	case a.Label == "":
+		return "[" + strings.Join(a.Tags, ",") + "]"
+	default:
+		return a.Label + " [" + strings.Join(a.Tags, ",") + "]"
+	}
+}
+
+// HasAnyTag 判断账户是否带有任一标签
+func (a AccountConfig) HasAnyTag(tags []string) bool {
+	for _, tag := range tags {
+		for _, own := range a.Tags {
+			if strings.EqualFold(tag, own) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DisplaySuffix 返回追加在账户序号后的标签文本，例如 " main [vps1,eu]"
+func (a AccountConfig) DisplaySuffix() string {
+	if display := a.Display(); display != "" {
+		return " " + display
+	}
+	return ""
+}
+
+// APIConfig 定义本地管理 API 配置
+type APIConfig struct {
+	Listen string `json:"listen,omitempty"`
+	Token  string `json:"token,omitempty"`
+}
+
+// 通知触发条件
+const (
+	TriggerAlways  = "always"
+	TriggerFailure = "failure"
+	TriggerStreak  = "streak"
+)
+
+// 通知渠道类型
+const (
+	NotifierWebhook  = "webhook"
+	NotifierTelegram = "telegram"
+	NotifierDiscord  = "discord"
+)
+
+// NotifierConfig 定义单个通知渠道
+type NotifierConfig struct {
+	Type       string `json:"type"`
+	URL        string `json:"url,omitempty"`
+	BaseURL    string `json:"base_url,omitempty"`
+	BotToken   string `json:"bot_token,omitempty"`
+	ChatID     string `json:"chat_id,omitempty"`
+	Template   string `json:"template,omitempty"`
+	Trigger    string `json:"trigger,omitempty"`
+	StreakDays int    `json:"streak_days,omitempty"`
+}
+
+// Validate 检查通知渠道类型、必填字段、触发条件与模板
+func (n NotifierConfig) Validate() error {
+	switch n.Type {
+	case NotifierWebhook, NotifierDiscord:
+		if n.URL == "" {
+			return fmt.Errorf("%s 通知缺少 url", n.Type)
+		}
+	case NotifierTelegram:
+		if n.BotToken == "" || n.ChatID == "" {
+			return fmt.Errorf("telegram 通知缺少 bot_token 或 chat_id")
+		}
+	default:
+		return fmt.Errorf("未知的通知类型: %q", n.Type)
+	}
+
+	switch n.Trigger {
+	case "", TriggerAlways, TriggerFailure, TriggerStreak:
+	default:
+		return fmt.Errorf("未知的通知触发条件: %q", n.Trigger)
+	}
+
+	if n.Template != "" {
+		if _, err := template.New(n.Type).Parse(n.Template); err != nil {
+			return fmt.Errorf("解析通知模板失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// DefaultActivities 未配置 campaign.activities 时每日需要领取的活动
+var DefaultActivities = []ActivityConfig{
+	{ID: "304a9530-3720-45c8-a778-fbd3060d5cfd"},
+	{ID: "e3e5f263-b471-4ef3-b285-77a66e358a69"},
+	{ID: "907b82a0-152f-45d7-ae35-ce01de22b481"},
+}
+
+// LoadOptions 加载配置的选项，通常来自命令行参数
+type LoadOptions struct {
+	// PassphraseRef 命令行 -passphrase 指定的口令来源，格式同配置中的密钥引用
+	PassphraseRef string
+	// Secrets 解析 ${...} 引用的密钥解析器，为空时新建一个。
+	// 重新加载配置时传入同一个解析器，外部命令与 HTTP 获取的密钥不会重复获取
+	Secrets *SecretResolver
+}
+
+// LoadOptions 返回加载该配置时使用的选项，重新加载配置时传给 Load
+func (c *Config) LoadOptions() LoadOptions {
+	return c.options
+}
+
+// Load 加载 JSON、YAML 或 TOML 配置文件 (可加密)，替换变量引用并检查全部账户与活动配置
+func Load(filename string, opts LoadOptions) (*Config, error) {
+	if opts.Secrets == nil {
+		opts.Secrets = NewSecretResolver(SecretsConfig{})
+	}
+	file, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+
+	format := Format(filename)
+	var passphrase string
+	if envelope, ok := ParseEncrypted(file); ok {
+		passphrase, err = opts.Passphrase("请输入配置文件口令: ")
+		if err != nil {
+			return nil, err
+		}
+		if file, err = envelope.Decrypt(passphrase); err != nil {
+			forgetPassphrase()
+			return nil, err
+		}
+		rememberPassphrase(passphrase)
+		defer clear(file)
+		format = envelope.Format
+	}
+
+	raw, err := Parse(format, file)
+	if err != nil {
+		return nil, parseError(err)
+	}
+	if err := interpolationError(opts.Secrets.configureSection(raw)); err != nil {
+		return nil, err
+	}
+	raw, errs := opts.Secrets.interpolateConfig(raw, "")
+	if err := interpolationError(errs); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, parseError(err)
+	}
+
+	problems := findUnknownFields(raw, reflect.TypeOf(config), "")
+	problems = append(problems, validateConfig(&config)...)
+	if len(problems) > 0 {
+		return nil, &Error{Problems: problems}
+	}
+
+	config.passphrase = passphrase
+	config.options = opts
+	return &config, nil
+}
+
+// parseError 配置文件格式错误，同样以 *Error 返回
+func parseError(err error) error {
+	return &Error{Problems: []string{fmt.Sprintf("解析配置文件失败: %v", err)}}
+}
+
+// CheckLoopback 确认监听地址为本机回环地址
+func CheckLoopback(listen string) error {
+	host, _, err := net.SplitHostPort(listen)
+	if err != nil {
+		return fmt.Errorf("无效的监听地址 %q: %v", listen, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("管理 API 只允许监听本机回环地址，当前为 %q", listen)
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package config
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+// encryptedConfigMagic 加密配置文件的标识
+const encryptedConfigMagic = "coinshift-encrypted-config"
+
+// 口令相关的环境变量
+const (
+	PassphraseEnv     = "COINSHIFT_CONFIG_PASSPHRASE"
+	PassphraseFileEnv = "COINSHIFT_CONFIG_PASSPHRASE_FILE"
+)
+
+// scrypt 参数
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+// EncryptedConfig 加密配置文件格式：scrypt 派生密钥，AES-256-GCM 加密原始配置
+type EncryptedConfig struct {
+	Magic      string `json:"magic"`
+	Version    int    `json:"version"`
+	Format     string `json:"format"`
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// additionalData 把格式与 KDF 参数绑定到密文，防止被篡改
+func (e *EncryptedConfig) additionalData() []byte {
+	return []byte(fmt.Sprintf("%s/%d/%s/%s/%d/%d/%d", e.Magic, e.Version, e.Format, e.KDF, e.N, e.R, e.P))
+}
+
+// ParseEncrypted 判断文件内容是否为加密配置
+func ParseEncrypted(data []byte) (*EncryptedConfig, bool) {
+	var envelope EncryptedConfig
+	if err := json.Unmarshal(data, &envelope); err != nil || envelope.Magic != encryptedConfigMagic {
+		return nil, false
+	}
+	return &envelope, true
+}
+
+// Encrypt 使用口令加密配置内容，format 为原始配置的扩展名
+func Encrypt(plaintext []byte, format, passphrase string) ([]byte, error) {
+	envelope := &EncryptedConfig{
+		Magic:   encryptedConfigMagic,
+		Version: 1,
+		Format:  format,
+		KDF:     "scrypt",
+		N:       scryptN,
+		R:       scryptR,
+		P:       scryptP,
+		Salt:    make([]byte, 16),
+	}
+	if _, err := rand.Read(envelope.Salt); err != nil {
+		return nil, fmt.Errorf("生成随机数失败: %v", err)
+	}
+	gcm, err := envelope.cipher(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	envelope.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(envelope.Nonce); err != nil {
+		return nil, fmt.Errorf("生成随机数失败: %v", err)
+	}
+	envelope.Ciphertext = gcm.Seal(nil, envelope.Nonce, plaintext, envelope.additionalData())
+	return json.MarshalIndent(envelope, "", "  ")
+}
+
+// Decrypt 使用口令解密配置内容
+func (e *EncryptedConfig) Decrypt(passphrase string) ([]byte, error) {
+	if e.Version != 1 || e.KDF != "scrypt" {
+		return nil, fmt.Errorf("不支持的加密配置版本 %d (%s)", e.Version, e.KDF)
+	}
+	if e.N > 1<<20 || e.R*e.P > 64 {
+		return nil, fmt.Errorf("加密配置的 scrypt 参数过大")
+	}
+	gcm, err := e.cipher(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if len(e.Nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("加密配置已损坏")
+	}
+	plaintext, err := gcm.Open(nil, e.Nonce, e.Ciphertext, e.additionalData())
+	if err != nil {
+		return nil, fmt.Errorf("解密配置失败，口令错误或文件已损坏")
+	}
+	return plaintext, nil
+}
+
+func (e *EncryptedConfig) cipher(passphrase string) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), e.Salt, e.N, e.R, e.P, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("派生密钥失败: %v", err)
+	}
+	defer clear(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Format 返回配置文件的格式扩展名，加密文件名中的 .enc 会被忽略
+func Format(filename string) string {
+	return strings.ToLower(filepath.Ext(strings.TrimSuffix(filename, ".enc")))
+}
+
+var (
+	passphraseMu     sync.Mutex
+	cachedPassphrase string
+)
+
+// Passphrase 按 -passphrase、环境变量、口令文件、终端输入的顺序获取口令。
+// 口令只有在 Load 成功解密配置后才缓存在内存中，口令错误时不会被后续加载复用
+func (o LoadOptions) Passphrase(prompt string) (string, error) {
+	passphraseMu.Lock()
+	cached := cachedPassphrase
+	passphraseMu.Unlock()
+	if cached != "" {
+		return cached, nil
+	}
+
+	var passphrase string
+	switch {
+	case o.PassphraseRef != "":
+		secrets := o.Secrets
+		if secrets == nil {
+			secrets = NewSecretResolver(SecretsConfig{})
+		}
+		value, err := secrets.Resolve(context.Background(), o.PassphraseRef)
+		if err != nil {
+			return "", fmt.Errorf("读取口令失败: %v", err)
+		}
+		passphrase = value
+	case os.Getenv(PassphraseEnv) != "":
+		passphrase = os.Getenv(PassphraseEnv)
+	case os.Getenv(PassphraseFileEnv) != "":
+		value, err := fileSecretProvider{}.Resolve(context.Background(), os.Getenv(PassphraseFileEnv))
+		if err != nil {
+			return "", fmt.Errorf("读取口令失败: %v", err)
+		}
+		passphrase = value
+	default:
+		value, err := readPassword(prompt)
+		if err != nil {
+			return "", err
+		}
+		passphrase = value
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("口令不能为空")
+	}
+	return passphrase, nil
+}
+
+// rememberPassphrase 解密成功后缓存口令，热重载时不再重复询问
+func rememberPassphrase(passphrase string) {
+	passphraseMu.Lock()
+	defer passphraseMu.Unlock()
+	cachedPassphrase = passphrase
+}
+
+// forgetPassphrase 解密失败时清除缓存的口令，下次加载重新获取
+func forgetPassphrase() {
+	passphraseMu.Lock()
+	defer passphraseMu.Unlock()
+	cachedPassphrase = ""
+}
+
+// NewPassphrase 获取加密用的口令，从终端输入时需要输入两次确认
+func (o LoadOptions) NewPassphrase() (string, error) {
+	if o.PassphraseRef != "" || os.Getenv(PassphraseEnv) != "" || os.Getenv(PassphraseFileEnv) != "" {
+		return o.Passphrase("")
+	}
+	first, err := readPassword("请输入新的配置文件口令: ")
+	if err != nil {
+		return "", err
+	}
+	second, err := readPassword("请再次输入口令: ")
+	if err != nil {
+		return "", err
+	}
+	if first != second {
+		return "", fmt.Errorf("两次输入的口令不一致")
+	}
+	if first == "" {
+		return "", fmt.Errorf("口令不能为空")
+	}
+	return first, nil
+}
+
+// readPassword 从终端读取口令，不回显
+func readPassword(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", errors.New("需要口令：请使用 -passphrase、环境变量 " + PassphraseEnv + " 或 " + PassphraseFileEnv + " 提供")
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("读取口令失败: %v", err)
+	}
+	return string(password), nil
+}
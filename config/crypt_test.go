@@ -0,0 +1,94 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEncryptRoundTrip(t *testing.T) {
+	plaintext := []byte(`{"accounts": []}`)
+	data, err := Encrypt(plaintext, ".json", "correct horse")
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+	if bytes.Contains(data, plaintext) {
+		t.Fatalf("密文包含明文")
+	}
+	envelope, ok := ParseEncrypted(data)
+	if !ok {
+		t.Fatalf("无法识别加密配置")
+	}
+	if envelope.Format != ".json" {
+		t.Errorf("格式为 %q，期望 .json", envelope.Format)
+	}
+	decrypted, err := envelope.Decrypt("correct horse")
+	if err != nil {
+		t.Fatalf("解密失败: %v", err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("解密结果不一致: %s", decrypted)
+	}
+
+	if _, err := envelope.Decrypt("wrong"); err == nil {
+		t.Errorf("口令错误时应返回错误")
+	}
+	envelope.Format = ".yaml"
+	if _, err := envelope.Decrypt("correct horse"); err == nil {
+		t.Errorf("格式被篡改时应返回错误")
+	}
+	if _, ok := ParseEncrypted(plaintext); ok {
+		t.Errorf("明文配置被识别为加密配置")
+	}
+}
+
+func TestLoadEncryptedCachesPassphraseOnlyAfterDecrypt(t *testing.T) {
+	t.Cleanup(forgetPassphrase)
+	forgetPassphrase()
+
+	plaintext := []byte(`{"accounts": [{"private_key": "` + testKey + `"}]}`)
+	data, err := Encrypt(plaintext, ".json", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "config.json.enc")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(PassphraseEnv, "wrong")
+	if _, err := Load(path, LoadOptions{}); err == nil {
+		t.Fatalf("口令错误时应加载失败")
+	}
+	if cachedPassphrase != "" {
+		t.Fatalf("口令错误时不应缓存")
+	}
+
+	t.Setenv(PassphraseEnv, "correct horse")
+	cfg, err := Load(path, LoadOptions{})
+	if err != nil {
+		t.Fatalf("加载加密配置失败: %v", err)
+	}
+	if cfg.EncryptionPassphrase() != "correct horse" || cachedPassphrase != "correct horse" {
+		t.Errorf("解密成功后应缓存口令")
+	}
+
+	// 配置换成其他口令加密后，缓存的口令解密失败并被清除
+	if data, err = Encrypt(plaintext, ".json", "new passphrase"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path, LoadOptions{}); err == nil {
+		t.Fatalf("缓存的口令不应能解密新配置")
+	}
+	if cachedPassphrase != "" {
+		t.Errorf("解密失败后应清除缓存的口令")
+	}
+	t.Setenv(PassphraseEnv, "new passphrase")
+	if _, err := Load(path, LoadOptions{}); err != nil {
+		t.Errorf("清除缓存后应使用新口令加载: %v", err)
+	}
+}
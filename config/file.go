@@ -0,0 +1,193 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// interpolationPattern 匹配 ${ENV_VAR}、${file:/path} 等密钥引用
+var interpolationPattern = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// Parse 按扩展名 (.json、.yaml、.yml、.toml) 解析配置，统一转成 JSON 的通用结构
+func Parse(format string, data []byte) (interface{}, error) {
+	var raw interface{}
+	switch format {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("解析 YAML 失败: %v", err)
+		}
+	case ".toml":
+		var table map[string]interface{}
+		if err := toml.Unmarshal(data, &table); err != nil {
+			return nil, fmt.Errorf("解析 TOML 失败: %v", err)
+		}
+		raw = table
+	default:
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, err
+		}
+	}
+	return normalizeConfigValue(raw)
+}
+
+// normalizeConfigValue 把 YAML/TOML 解析出的值转换为 encoding/json 可以处理的结构
+func normalizeConfigValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			normalized, err := normalizeConfigValue(item)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = normalized
+		}
+		return v, nil
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			name, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("字段名 %v 必须是字符串", key)
+			}
+			normalized, err := normalizeConfigValue(item)
+			if err != nil {
+				return nil, err
+			}
+			out[name] = normalized
+		}
+		return out, nil
+	case []interface{}:
+		for i, item := range v {
+			normalized, err := normalizeConfigValue(item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = normalized
+		}
+		return v, nil
+	case []map[string]interface{}:
+		// TOML 的 [[accounts]] 数组
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			normalized, err := normalizeConfigValue(item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = normalized
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// interpolateConfig 替换所有字符串字段中的密钥引用，返回无法解析的引用
+func (r *SecretResolver) interpolateConfig(value interface{}, path string) (interface{}, []error) {
+	var problems []error
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			var itemProblems []error
+			v[key], itemProblems = r.interpolateConfig(item, joinPath(path, key))
+			problems = append(problems, itemProblems...)
+		}
+	case []interface{}:
+		for i, item := range v {
+			var itemProblems []error
+			v[i], itemProblems = r.interpolateConfig(item, fmt.Sprintf("%s[%d]", path, i))
+			problems = append(problems, itemProblems...)
+		}
+	case string:
+		result, err := r.interpolateString(v)
+		if err != nil {
+			return v, []error{fmt.Errorf("%s: %w", describeField(path), err)}
+		}
+		return result, nil
+	}
+	return value, problems
+}
+
+// interpolateString 替换单个字符串中的引用，错误信息中不包含解析出的值
+func (r *SecretResolver) interpolateString(s string) (string, error) {
+	var firstErr error
+	result := interpolationPattern.ReplaceAllStringFunc(s, func(match string) string {
+		ref := match[2 : len(match)-1]
+		value, err := r.Resolve(context.Background(), ref)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return match
+		}
+		return value
+	})
+	return result, firstErr
+}
+
+// describeField 返回字段路径的描述，账户字段带账户序号
+func describeField(path string) string {
+	var index int
+	var rest string
+	if n, _ := fmt.Sscanf(path, "accounts[%d].%s", &index, &rest); n == 2 {
+		return fmt.Sprintf("账户 #%d %s", index+1, rest)
+	}
+	return path
+}
+
+// interpolationError 汇总替换引用时的问题。引用写法有误时返回 *Error；
+// 写法正确但密钥来源读取失败 (命令超时、HTTP 服务不可用等) 时返回普通错误，不视为配置错误
+func interpolationError(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	var problems []string
+	configProblem := false
+	for _, err := range errs {
+		problems = append(problems, err.Error())
+		var unavailable *unavailableError
+		if !errors.As(err, &unavailable) {
+			configProblem = true
+		}
+	}
+	if configProblem {
+		return &Error{Problems: problems}
+	}
+	return fmt.Errorf("读取密钥失败:\n  %s", strings.Join(problems, "\n  "))
+}
+
+// configureSection 先解析配置中的 secrets 段 (其中只能引用环境变量与文件)，再用它配置密钥来源
+func (r *SecretResolver) configureSection(raw interface{}) []error {
+	object, ok := raw.(map[string]interface{})
+	if !ok || object["secrets"] == nil {
+		r.Configure(SecretsConfig{})
+		return nil
+	}
+	section, problems := r.interpolateConfig(object["secrets"], "secrets")
+	if len(problems) > 0 {
+		return problems
+	}
+	object["secrets"] = section
+
+	data, err := json.Marshal(section)
+	if err != nil {
+		return []error{fmt.Errorf("secrets: %v", err)}
+	}
+	var config SecretsConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return []error{fmt.Errorf("secrets: %v", err)}
+	}
+	if config.HTTP != nil {
+		if err := checkHTTPURL(config.HTTP.URL); err != nil {
+			return []error{fmt.Errorf("secrets.http.url: %v", err)}
+		}
+	}
+	r.Configure(config)
+	return nil
+}
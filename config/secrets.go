@@ -0,0 +1,246 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 密钥引用的来源，写作 ${<来源>:<引用>}，省略来源时读取环境变量
+const (
+	SecretEnv  = "env"
+	SecretFile = "file"
+	SecretCmd  = "cmd"
+	SecretHTTP = "http"
+)
+
+const (
+	defaultSecretTimeout = 10 * time.Second
+	defaultSecretField   = "value"
+)
+
+// SecretProvider 根据引用读取私钥、口令、Refresh Token 等密钥
+type SecretProvider interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// SecretsConfig 定义密钥来源的参数
+type SecretsConfig struct {
+	// Timeout 外部命令与 HTTP 请求的超时时间 (秒)，默认 10 秒
+	Timeout int `json:"timeout,omitempty"`
+	// HTTP 通用 HTTP JSON 密钥服务
+	HTTP *HTTPSecretConfig `json:"http,omitempty"`
+}
+
+// HTTPSecretConfig 定义 HTTP 密钥服务: GET <url>/<引用>，从返回的 JSON 中读取 field 字段
+type HTTPSecretConfig struct {
+	URL   string `json:"url"`
+	Token string `json:"token,omitempty"`
+	// Field 密钥所在的 JSON 字段，支持 data.value 形式的嵌套路径，默认 value
+	Field string `json:"field,omitempty"`
+}
+
+// envSecretProvider 读取环境变量
+type envSecretProvider struct{}
+
+func (envSecretProvider) Resolve(ctx context.Context, name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("空的变量引用 ${}")
+	}
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("环境变量 %s 未设置", name)
+	}
+	return value, nil
+}
+
+// fileSecretProvider 读取文件内容，去掉末尾换行
+type fileSecretProvider struct{}
+
+func (fileSecretProvider) Resolve(ctx context.Context, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("读取 ${file:%s} 失败: %v", path, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// commandSecretProvider 执行外部命令 (例如 pass show coinshift/key1)，标准输出作为密钥
+type commandSecretProvider struct {
+	timeout time.Duration
+}
+
+func (p commandSecretProvider) Resolve(ctx context.Context, command string) (string, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return "", fmt.Errorf("空的命令引用 ${cmd:}")
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("执行 ${cmd:%s} 失败: %v %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	// pass 等工具的第一行为密钥，其余行为备注
+	secret, _, _ := strings.Cut(string(output), "\n")
+	secret = strings.TrimRight(secret, "\r")
+	if secret == "" {
+		return "", fmt.Errorf("${cmd:%s} 没有输出", args[0])
+	}
+	return secret, nil
+}
+
+// httpSecretProvider 从 HTTP JSON 密钥服务读取密钥
+type httpSecretProvider struct {
+	config HTTPSecretConfig
+	client *http.Client
+}
+
+func (p httpSecretProvider) Resolve(ctx context.Context, name string) (string, error) {
+	endpoint := strings.TrimRight(p.config.URL, "/") + "/" + url.PathEscape(name)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if p.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.config.Token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("读取 ${http:%s} 失败: %v", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return "", fmt.Errorf("读取 ${http:%s} 失败: 非预期状态码 %d", name, resp.StatusCode)
+	}
+
+	var body interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("解析 ${http:%s} 响应失败: %v", name, err)
+	}
+	field := p.config.Field
+	if field == "" {
+		field = defaultSecretField
+	}
+	value := body
+	for _, key := range strings.Split(field, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			value = nil
+			break
+		}
+		value = object[key]
+	}
+	secret, ok := value.(string)
+	if !ok || secret == "" {
+		return "", fmt.Errorf("${http:%s} 响应中缺少字段 %s", name, field)
+	}
+	return secret, nil
+}
+
+// SecretResolver 按来源分发密钥引用，外部命令与 HTTP 的解析结果只缓存在内存中，不写入磁盘
+type SecretResolver struct {
+	mu        sync.Mutex
+	config    SecretsConfig
+	providers map[string]SecretProvider
+	cache     map[string]string
+}
+
+// NewSecretResolver 根据配置创建密钥解析器
+func NewSecretResolver(config SecretsConfig) *SecretResolver {
+	r := &SecretResolver{}
+	r.Configure(config)
+	return r
+}
+
+// Configure 更新密钥来源配置，配置变化时清空缓存
+func (r *SecretResolver) Configure(config SecretsConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.providers != nil && reflect.DeepEqual(r.config, config) {
+		return
+	}
+
+	timeout := defaultSecretTimeout
+	if config.Timeout > 0 {
+		timeout = time.Duration(config.Timeout) * time.Second
+	}
+	r.config = config
+	r.cache = make(map[string]string)
+	r.providers = map[string]SecretProvider{
+		SecretEnv:  envSecretProvider{},
+		SecretFile: fileSecretProvider{},
+		SecretCmd:  commandSecretProvider{timeout: timeout},
+	}
+	if config.HTTP != nil {
+		r.providers[SecretHTTP] = httpSecretProvider{
+			config: *config.HTTP,
+			client: &http.Client{Timeout: timeout},
+		}
+	}
+}
+
+// unavailableError 密钥来源读取失败，引用本身的写法没有问题
+type unavailableError struct {
+	err error
+}
+
+func (e *unavailableError) Error() string { return e.err.Error() }
+
+func (e *unavailableError) Unwrap() error { return e.err }
+
+// Resolve 解析 ${...} 中的引用，例如 MY_KEY、file:/run/secrets/key、cmd:pass show key1、http:key1
+func (r *SecretResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	scheme, name := SecretEnv, ref
+	if prefix, rest, ok := strings.Cut(ref, ":"); ok {
+		switch prefix {
+		case SecretEnv, SecretFile, SecretCmd, SecretHTTP:
+			scheme, name = prefix, rest
+		}
+	}
+	key := scheme + ":" + name
+	// 环境变量与文件每次重新读取，外部命令与 HTTP 的结果缓存在内存中
+	cacheable := scheme == SecretCmd || scheme == SecretHTTP
+
+	r.mu.Lock()
+	value, cached := r.cache[key]
+	provider := r.providers[scheme]
+	r.mu.Unlock()
+	if cacheable && cached {
+		return value, nil
+	}
+	if provider == nil {
+		return "", fmt.Errorf("引用了 ${%s:...}，但未配置 secrets.%s", scheme, scheme)
+	}
+
+	value, err := provider.Resolve(ctx, name)
+	if err != nil {
+		if name == "" {
+			return "", err
+		}
+		return "", &unavailableError{err: err}
+	}
+	if cacheable {
+		r.mu.Lock()
+		r.cache[key] = value
+		r.mu.Unlock()
+	}
+	return value, nil
+}
@@ -0,0 +1,255 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"blockmesh/signer"
+)
+
+// activityIDPattern Deform 活动ID为 UUID
+var activityIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Error 配置文件校验失败，包含发现的全部问题
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("配置文件存在 %d 个问题:\n  %s", len(e.Problems), strings.Join(e.Problems, "\n  "))
+}
+
+// validateConfig 检查配置中的账户、代理、活动与通知设置，返回发现的全部问题
+func validateConfig(config *Config) []string {
+	var problems []string
+	add := func(format string, v ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, v...))
+	}
+
+	if len(config.Accounts) == 0 {
+		add("accounts: 未配置任何账户")
+	}
+	seen := make(map[string]int)
+	for i, account := range config.Accounts {
+		if !signer.ValidPrivateKey(account.PrivateKey) {
+			add("账户 #%d: 私钥格式无效，应为 64 位十六进制 (可带 0x 前缀)", i+1)
+		} else if address, err := signer.GetAddressFromPrivateKey(account.PrivateKey); err != nil {
+			add("账户 #%d: 私钥无效: %v", i+1, err)
+		} else {
+			key := strings.ToLower(address)
+			if first, ok := seen[key]; ok {
+				add("账户 #%d: 地址 %s 与账户 #%d 重复", i+1, address, first+1)
+			} else {
+				seen[key] = i
+			}
+			if account.ExpectedAddress != "" && common.IsHexAddress(account.ExpectedAddress) && !strings.EqualFold(address, account.ExpectedAddress) {
+				add("账户 #%d: 私钥对应的地址 %s 与 expected_address %s 不一致", i+1, address, account.ExpectedAddress)
+			}
+		}
+		if account.ExpectedAddress != "" && !common.IsHexAddress(account.ExpectedAddress) {
+			add("账户 #%d: expected_address %q 不是有效的地址", i+1, account.ExpectedAddress)
+		}
+		if account.Proxy != "" {
+			if err := checkProxyURL(account.Proxy); err != nil {
+				add("账户 #%d: %v", i+1, err)
+			}
+		}
+		for _, tag := range account.Tags {
+			if strings.TrimSpace(tag) == "" || strings.Contains(tag, ",") {
+				add("账户 #%d: 标签 %q 不能为空或包含逗号", i+1, tag)
+			}
+		}
+	}
+
+	problems = append(problems, validateCampaign(config.Campaign)...)
+
+	if config.Endpoints.PrivyBaseURL != "" {
+		if err := checkHTTPURL(config.Endpoints.PrivyBaseURL); err != nil {
+			add("endpoints.privy_base_url: %v", err)
+		}
+	}
+	if config.Endpoints.DeformAPIURL != "" {
+		if err := checkHTTPURL(config.Endpoints.DeformAPIURL); err != nil {
+			add("endpoints.deform_api_url: %v", err)
+		}
+	}
+	if config.API.Listen != "" {
+		if err := CheckLoopback(config.API.Listen); err != nil {
+			add("api.listen: %v", err)
+		}
+	}
+	if config.HTTP.Timeout < 0 {
+		add("http.timeout: 不能为负数")
+	}
+	stages := make([]string, 0, len(config.HTTP.StageTimeouts))
+	for stage := range config.HTTP.StageTimeouts {
+		stages = append(stages, stage)
+	}
+	sort.Strings(stages)
+	for _, stage := range stages {
+		if seconds := config.HTTP.StageTimeouts[stage]; !slices.Contains(TimeoutStages, stage) {
+			add("http.stage_timeouts: 未知的阶段 %q (可选: %s)", stage, strings.Join(TimeoutStages, ", "))
+		} else if seconds <= 0 {
+			add("http.stage_timeouts.%s: 必须大于 0", stage)
+		}
+	}
+	if config.Clock.SkewWarning < 0 {
+		add("clock.skew_warning: 不能为负数")
+	}
+	if config.Retries != nil && *config.Retries < 0 {
+		add("retries: 不能为负数")
+	}
+	for i, notifier := range config.Notifiers {
+		if err := notifier.Validate(); err != nil {
+			add("通知 #%d: %v", i+1, err)
+		}
+	}
+	return problems
+}
+
+// validateCampaign 检查活动配置：活动ID格式、重复与是否全部停用
+func validateCampaign(campaign CampaignConfig) []string {
+	var problems []string
+	if campaign.ID != strings.TrimSpace(campaign.ID) {
+		problems = append(problems, "campaign.id: 包含多余的空白字符")
+	}
+	seen := make(map[string]int)
+	enabled := 0
+	for i, activity := range campaign.Activities {
+		switch {
+		case activity.ID == "":
+			problems = append(problems, fmt.Sprintf("活动 #%d: 缺少 id", i+1))
+		case !activityIDPattern.MatchString(activity.ID):
+			problems = append(problems, fmt.Sprintf("活动 #%d: id %q 不是有效的 UUID", i+1, activity.ID))
+		default:
+			if first, ok := seen[strings.ToLower(activity.ID)]; ok {
+				problems = append(problems, fmt.Sprintf("活动 #%d: id %s 与活动 #%d 重复", i+1, activity.ID, first+1))
+			}
+			seen[strings.ToLower(activity.ID)] = i
+		}
+		if !activity.Disabled {
+			enabled++
+		}
+	}
+	if len(campaign.Activities) > 0 && enabled == 0 {
+		problems = append(problems, "campaign.activities: 所有活动都已停用")
+	}
+	return problems
+}
+
+// checkProxyURL 检查代理地址格式，错误信息中不包含代理密码
+func checkProxyURL(proxyURL string) error {
+	u, err := url.Parse(proxyURL)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("代理地址无效，应为 http://host:port 格式")
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("代理协议 %q 不受支持，可选 http、https、socks5", u.Scheme)
+	}
+	if u.Port() == "" {
+		return fmt.Errorf("代理地址缺少端口")
+	}
+	return nil
+}
+
+// checkHTTPURL 检查上游服务地址格式
+func checkHTTPURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("%q 不是有效的 http(s) 地址", rawURL)
+	}
+	return nil
+}
+
+// findUnknownFields 对比 JSON 与配置结构体，找出全部拼写错误或不支持的字段
+func findUnknownFields(value interface{}, t reflect.Type, path string) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var problems []string
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		fields := jsonFields(t)
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fieldType, ok := fields[key]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s: 未知字段 %q", describePath(path), key))
+				continue
+			}
+			problems = append(problems, findUnknownFields(object[key], fieldType, joinPath(path, key))...)
+		}
+	case reflect.Slice:
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil
+		}
+		for i, item := range items {
+			problems = append(problems, findUnknownFields(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+	return problems
+}
+
+// jsonFields 返回结构体的 JSON 字段名与类型，包含嵌入的结构体字段
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			for key, fieldType := range jsonFields(field.Type) {
+				fields[key] = fieldType
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// describePath 把 accounts[0] 这类路径转成带账户序号的描述
+func describePath(path string) string {
+	if path == "" {
+		return "配置文件"
+	}
+	var index int
+	var rest string
+	if n, _ := fmt.Sscanf(path, "accounts[%d]%s", &index, &rest); n >= 1 {
+		return fmt.Sprintf("账户 #%d%s", index+1, rest)
+	}
+	return path
+}
@@ -0,0 +1,89 @@
+package deform
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"blockmesh/internal/httpclient"
+)
+
+// StageCampaign 查询活动列表的阶段名
+const StageCampaign = "campaign-activities"
+
+// CampaignActivity 活动中的单个任务
+type CampaignActivity struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Type     string `json:"type"`
+	Typename string `json:"__typename"`
+}
+
+// CampaignInfo 活动信息与任务列表
+type CampaignInfo struct {
+	ID         string             `json:"id"`
+	Name       string             `json:"name"`
+	Activities []CampaignActivity `json:"activities"`
+	Typename   string             `json:"__typename"`
+}
+
+// CampaignResponse 定义查询活动响应结构体
+type CampaignResponse struct {
+	Data struct {
+		Campaign *CampaignInfo `json:"campaign"`
+	} `json:"data"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
+
+// QueryCampaignActivities 查询活动下的全部任务，无需登录
+func (c *Client) QueryCampaignActivities(ctx context.Context, campaignID, proxyURL string) (*CampaignInfo, error) {
+	// 1. 构造 GraphQL 请求
+	requestBody := GraphQLRequest{
+		OperationName: "CampaignActivities",
+		Variables: map[string]interface{}{
+			"campaignId": campaignID,
+		},
+		Query: `query CampaignActivities($campaignId: String!) {
+  campaign(id: $campaignId) {
+    id
+    name
+    activities {
+      id
+      title
+      type
+      __typename
+    }
+    __typename
+  }
+}`,
+	}
+	requestBodyBytes, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求体失败: %v", err)
+	}
+
+	// 2. 创建HTTP请求
+	req, err := http.NewRequestWithContext(httpclient.WithStage(ctx, StageCampaign), "POST", c.APIURL, bytes.NewBuffer(requestBodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+	setRequestHeaders(req)
+	req.Header.Set("x-apollo-operation-name", "CampaignActivities")
+
+	// 3. 发送请求并解析响应体
+	var response CampaignResponse
+	if err := c.send(req, proxyURL, &response); err != nil {
+		return nil, err
+	}
+
+	// 4. 检查 GraphQL 错误
+	if len(response.Errors) > 0 {
+		return nil, fmt.Errorf("GraphQL错误: %v", response.Errors[0].Message)
+	}
+	if response.Data.Campaign == nil {
+		return nil, fmt.Errorf("活动 %s 不存在", campaignID)
+	}
+	return response.Data.Campaign, nil
+}
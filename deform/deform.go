@@ -0,0 +1,447 @@
+// Package deform 实现 Deform GraphQL 接口：登录、领取活动、查询用户资料与活动列表，
+// 并把 Deform 返回的错误归类为已领取、冷却中、不符合条件等领取结果。
+package deform
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"blockmesh/internal/httpclient"
+	"blockmesh/internal/logging"
+)
+
+// DefaultAPIURL 默认的 Deform GraphQL 接口地址
+const DefaultAPIURL = "https://api.deform.cc/"
+
+// Client Deform 接口客户端
+type Client struct {
+	// APIURL GraphQL 接口地址，可指向本地假服务
+	APIURL string
+	// HTTP 发送请求使用的连接池、超时与录制/回放设置
+	HTTP *httpclient.Client
+}
+
+// NewClient 创建客户端，apiURL 为空时使用默认地址，使用默认超时
+func NewClient(apiURL string) *Client {
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	return &Client{APIURL: apiURL, HTTP: httpclient.New(nil, httpclient.Timeouts{}, nil)}
+}
+
+// 请求所属的流程阶段，用于给录制文件命名
+const (
+	StageLogin          = "deform-login"
+	StageVerifyActivity = "verify-activity"
+	StageUserProfile    = "user-profile"
+)
+
+// GraphQLRequest 定义 GraphQL 请求结构体
+type GraphQLRequest struct {
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+	Query         string                 `json:"query"`
+}
+
+// GraphQLError 定义 GraphQL 错误
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// GraphQLResponse 定义 GraphQL 响应结构体
+type GraphQLResponse struct {
+	Data struct {
+		UserLogin string `json:"userLogin"`
+	} `json:"data"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
+
+// RewardRecord 定义奖励发放记录
+type RewardRecord struct {
+	ID                    string `json:"id"`
+	Status                string `json:"status"`
+	AppliedRewardType     string `json:"appliedRewardType"`
+	AppliedRewardQuantity int    `json:"appliedRewardQuantity"`
+	AppliedRewardMetadata any    `json:"appliedRewardMetadata"`
+	Error                 any    `json:"error"`
+	RewardID              string `json:"rewardId"`
+	Reward                struct {
+		ID         string   `json:"id"`
+		Quantity   int      `json:"quantity"`
+		Type       string   `json:"type"`
+		Properties struct{} `json:"properties"`
+		Typename   string   `json:"__typename"`
+	} `json:"reward"`
+	Typename string `json:"__typename"`
+}
+
+// RewardQuantity 返回实际发放的奖励数量，未发放时使用奖励定义中的数量
+func RewardQuantity(reward RewardRecord) int {
+	if reward.AppliedRewardQuantity != 0 {
+		return reward.AppliedRewardQuantity
+	}
+	return reward.Reward.Quantity
+}
+
+// RewardType 返回实际发放的奖励类型
+func RewardType(reward RewardRecord) string {
+	if reward.AppliedRewardType != "" {
+		return reward.AppliedRewardType
+	}
+	return reward.Reward.Type
+}
+
+// ActivityRecord 定义活动完成记录
+type ActivityRecord struct {
+	ID            string         `json:"id"`
+	ActivityID    string         `json:"activityId"`
+	Status        string         `json:"status"`
+	Properties    any            `json:"properties"`
+	CreatedAt     string         `json:"createdAt"`
+	RewardRecords []RewardRecord `json:"rewardRecords"`
+	Typename      string         `json:"__typename"`
+}
+
+// MissionRecord 定义任务完成记录，活动完成后触发任务时返回
+type MissionRecord struct {
+	ID            string         `json:"id"`
+	MissionID     string         `json:"missionId"`
+	Status        string         `json:"status"`
+	CreatedAt     string         `json:"createdAt"`
+	RewardRecords []RewardRecord `json:"rewardRecords"`
+	Typename      string         `json:"__typename"`
+}
+
+// VerifyActivityResult 定义领取活动的结果
+type VerifyActivityResult struct {
+	Record        ActivityRecord `json:"record"`
+	MissionRecord *MissionRecord `json:"missionRecord"`
+	Typename      string         `json:"__typename"`
+}
+
+// VerifyActivityResponse 定义领取活动响应结构体
+type VerifyActivityResponse struct {
+	Data struct {
+		VerifyActivity *VerifyActivityResult `json:"verifyActivity"`
+	} `json:"data"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
+
+// CampaignSpot 定义用户在活动中的积分与排名
+type CampaignSpot struct {
+	ID           string `json:"id"`
+	Points       int    `json:"points"`
+	Rank         int    `json:"rank"`
+	ReferralCode string `json:"referralCode"`
+	Typename     string `json:"__typename"`
+}
+
+// UserProfile 定义 Deform 用户资料
+type UserProfile struct {
+	ID           string        `json:"id"`
+	DisplayName  string        `json:"displayName"`
+	CampaignSpot *CampaignSpot `json:"campaignSpot"`
+	Typename     string        `json:"__typename"`
+}
+
+// UserMeResponse 定义查询用户资料响应结构体
+type UserMeResponse struct {
+	Data struct {
+		UserMe UserProfile `json:"userMe"`
+	} `json:"data"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
+
+// NewLoginRequest 构造 deform.cc 登录请求
+func (c *Client) NewLoginRequest(ctx context.Context, authToken string) (*http.Request, error) {
+	// 1. 准备请求URL
+	url := c.APIURL
+
+	// 2. 构造 GraphQL 请求
+	requestBody := GraphQLRequest{
+		OperationName: "UserLogin",
+		Variables: map[string]interface{}{
+			"data": map[string]string{
+				"externalAuthToken": authToken,
+			},
+		},
+		Query: `mutation UserLogin($data: UserLoginInput!) {
+			userLogin(data: $data)
+		}`,
+	}
+
+	// 3. 序列化请求体
+	requestBodyBytes, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求体失败: %v", err)
+	}
+
+	// 4. 创建HTTP请求
+	req, err := http.NewRequestWithContext(httpclient.WithStage(ctx, StageLogin), "POST", url, bytes.NewBuffer(requestBodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+
+	// 5. 设置请求头
+	setRequestHeaders(req)
+	return req, nil
+}
+
+// Login 向 deform.cc 发送登录请求，返回 Deform Token
+func (c *Client) Login(ctx context.Context, authToken, proxyURL string) (string, error) {
+	// 1. 构造请求
+	req, err := c.NewLoginRequest(ctx, authToken)
+	if err != nil {
+		return "", err
+	}
+
+	// 2. 发送请求并解析响应体
+	var response GraphQLResponse
+	if err := c.send(req, proxyURL, &response); err != nil {
+		return "", err
+	}
+
+	// 3. 检查 GraphQL 错误
+	if len(response.Errors) > 0 {
+		return "", fmt.Errorf("GraphQL错误: %v", response.Errors[0].Message)
+	}
+
+	return response.Data.UserLogin, nil
+}
+
+// NewVerifyActivityRequest 构造领取活动请求
+func (c *Client) NewVerifyActivityRequest(ctx context.Context, activityId, bearerToken, privyIdToken string) (*http.Request, error) {
+	// 1. 准备请求URL
+	uri := c.APIURL
+
+	// 2. 构造 GraphQL 请求
+	requestBody := GraphQLRequest{
+		OperationName: "VerifyActivity",
+		Variables: map[string]interface{}{
+			"data": map[string]interface{}{
+				"activityId": activityId,
+			},
+		},
+		Query: `mutation VerifyActivity($data: VerifyActivityInput!) {
+  verifyActivity(data: $data) {
+    record {
+      id
+      activityId
+      status
+      properties
+      createdAt
+      rewardRecords {
+        id
+        status
+        appliedRewardType
+        appliedRewardQuantity
+        appliedRewardMetadata
+        error
+        rewardId
+        reward {
+          id
+          quantity
+          type
+          properties
+          __typename
+        }
+        __typename
+      }
+      __typename
+    }
+    missionRecord {
+      id
+      missionId
+      status
+      createdAt
+      rewardRecords {
+        id
+        status
+        appliedRewardType
+        appliedRewardQuantity
+        appliedRewardMetadata
+        error
+        rewardId
+        reward {
+          id
+          quantity
+          type
+          properties
+          __typename
+        }
+        __typename
+      }
+      __typename
+    }
+    __typename
+  }
+}`,
+	}
+
+	// 3. 序列化请求体
+	requestBodyBytes, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求体失败: %v", err)
+	}
+
+	// 4. 创建HTTP请求
+	req, err := http.NewRequestWithContext(httpclient.WithStage(ctx, StageVerifyActivity+"-"+activityId), "POST", uri, bytes.NewBuffer(requestBodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+
+	// 5. 设置请求头
+	setRequestHeaders(req)
+	req.Header.Set("Authorization", "Bearer "+bearerToken)
+	req.Header.Set("Privy-Id-Token", privyIdToken)
+	return req, nil
+}
+
+// VerifyActivity 领取指定活动奖励
+func (c *Client) VerifyActivity(ctx context.Context, activityId, bearerToken, privyIdToken, proxyURL string) (*VerifyActivityResult, error) {
+	// 1. 构造请求
+	req, err := c.NewVerifyActivityRequest(ctx, activityId, bearerToken, privyIdToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 发送请求并解析响应体
+	var response VerifyActivityResponse
+	if err := c.send(req, proxyURL, &response); err != nil {
+		return nil, err
+	}
+
+	// 3. 检查 GraphQL 错误，区分已领取、冷却中、不符合条件等情况
+	if len(response.Errors) > 0 {
+		return nil, ClassifyGraphQLError(response.Errors[0])
+	}
+	if response.Data.VerifyActivity == nil {
+		return nil, fmt.Errorf("响应中缺少 verifyActivity")
+	}
+	logging.Info("完成任务状态：%s", response.Data.VerifyActivity.Record.Status)
+	if err := ClassifyRecordStatus(response.Data.VerifyActivity.Record.Status); err != nil {
+		return response.Data.VerifyActivity, err
+	}
+	return response.Data.VerifyActivity, nil
+}
+
+// QueryUserProfile 查询当前登录用户的资料、积分与排名
+func (c *Client) QueryUserProfile(ctx context.Context, campaignID, bearerToken, privyIdToken, proxyURL string) (*UserProfile, error) {
+	// 1. 准备请求URL
+	uri := c.APIURL
+
+	// 2. 构造 GraphQL 请求
+	requestBody := GraphQLRequest{
+		OperationName: "UserMe",
+		Variables: map[string]interface{}{
+			"campaignId": campaignID,
+		},
+		Query: `query UserMe($campaignId: String!) {
+  userMe {
+    id
+    displayName
+    campaignSpot(campaignId: $campaignId) {
+      id
+      points
+      rank
+      referralCode
+      __typename
+    }
+    __typename
+  }
+}`,
+	}
+
+	// 3. 序列化请求体
+	requestBodyBytes, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求体失败: %v", err)
+	}
+
+	// 4. 创建HTTP请求
+	req, err := http.NewRequestWithContext(httpclient.WithStage(ctx, StageUserProfile), "POST", uri, bytes.NewBuffer(requestBodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+
+	// 5. 设置请求头
+	setRequestHeaders(req)
+	req.Header.Set("x-apollo-operation-name", "UserMe")
+	req.Header.Set("Authorization", "Bearer "+bearerToken)
+	req.Header.Set("Privy-Id-Token", privyIdToken)
+
+	// 6. 发送请求并解析响应体
+	var response UserMeResponse
+	if err := c.send(req, proxyURL, &response); err != nil {
+		return nil, err
+	}
+
+	// 7. 检查 GraphQL 错误
+	if len(response.Errors) > 0 {
+		return nil, fmt.Errorf("GraphQL错误: %v", response.Errors[0].Message)
+	}
+
+	return &response.Data.UserMe, nil
+}
+
+// send 发送请求并把 JSON 响应解析到 v
+func (c *Client) send(req *http.Request, proxyURL string, v interface{}) error {
+	client, err := c.HTTP.ForRequest(req, proxyURL)
+	if err != nil {
+		return fmt.Errorf("创建HTTP客户端失败: %v", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求发送失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("解析响应失败: %v", err)
+	}
+	return nil
+}
+
+// APIError Deform 返回的非 200 响应
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("非预期状态码: %d, 响应: %s", e.StatusCode, e.Body)
+}
+
+// setRequestHeaders 设置 deform.cc 请求头
+func setRequestHeaders(req *http.Request) {
+	headers := map[string]string{
+		"accept":                  "*/*",
+		"accept-encoding":         "gzip, deflate, br, zstd",
+		"accept-language":         "zh-CN,zh;q=0.9,en;q=0.8",
+		"content-type":            "application/json",
+		"origin":                  "https://campaign.coinshift.xyz",
+		"priority":                "u=1, i",
+		"referer":                 "https://campaign.coinshift.xyz/",
+		"sec-ch-ua":               `"Google Chrome";v="137", "Chromium";v="137", "Not/A)Brand";v="24"`,
+		"sec-ch-ua-mobile":        "?0",
+		"sec-ch-ua-platform":      `"macOS"`,
+		"sec-fetch-dest":          "empty",
+		"sec-fetch-mode":          "cors",
+		"sec-fetch-site":          "cross-site",
+		"user-agent":              "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/137.0.0.0 Safari/537.36",
+		"x-apollo-operation-name": "UserLogin",
+	}
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+}
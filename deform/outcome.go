@@ -0,0 +1,185 @@
+package deform
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Outcome 活动领取结果类型
+type Outcome string
+
+const (
+	OutcomeCompleted        Outcome = "completed"
+	OutcomeAlreadyCompleted Outcome = "already_completed"
+	OutcomeOnCooldown       Outcome = "on_cooldown"
+	OutcomeIneligible       Outcome = "ineligible"
+	OutcomeFailed           Outcome = "failed"
+)
+
+// Succeeded 已完成、已领取过与冷却中都不算失败，冷却中的活动跳过，到下次可领取时间再领取
+func (o Outcome) Succeeded() bool {
+	return o == OutcomeCompleted || o == OutcomeAlreadyCompleted || o == OutcomeOnCooldown
+}
+
+// Retryable 只有真正的失败才需要重试
+func (o Outcome) Retryable() bool {
+	return o == OutcomeFailed
+}
+
+// ActivityError 领取活动时 Deform 返回的业务错误
+type ActivityError struct {
+	Outcome        Outcome
+	Code           string
+	Message        string
+	NextEligibleAt time.Time
+}
+
+func (e *ActivityError) Error() string {
+	msg := e.Message
+	if e.Code != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, e.Code)
+	}
+	if !e.NextEligibleAt.IsZero() {
+		msg = fmt.Sprintf("%s，下次可领取时间: %s", msg, e.NextEligibleAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	return msg
+}
+
+// ClassifyOutcome 根据 VerifyActivity 返回的错误判断领取结果与下次可领取时间
+func ClassifyOutcome(err error) (Outcome, time.Time) {
+	if err == nil {
+		return OutcomeCompleted, time.Time{}
+	}
+	var activityErr *ActivityError
+	if errors.As(err, &activityErr) {
+		return activityErr.Outcome, activityErr.NextEligibleAt
+	}
+	return OutcomeFailed, time.Time{}
+}
+
+// IsAuthError 判断错误是否由登录会话失效引起：HTTP 401，或错误码为 UNAUTHENTICATED/UNAUTHORIZED
+func IsAuthError(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusUnauthorized
+	}
+	var activityErr *ActivityError
+	if !errors.As(err, &activityErr) {
+		return false
+	}
+	switch strings.ToUpper(activityErr.Code) {
+	case "UNAUTHENTICATED", "UNAUTHORIZED":
+		return true
+	}
+	return containsAny(strings.ToLower(activityErr.Message), "jwt expired", "invalid token")
+}
+
+// ClassifyRecordStatus 根据活动记录状态判断是否领取成功
+func ClassifyRecordStatus(status string) error {
+	switch strings.ToUpper(status) {
+	case "FAILED", "REJECTED", "ERROR":
+		return &ActivityError{Outcome: OutcomeFailed, Code: status, Message: "活动记录状态异常"}
+	}
+	return nil
+}
+
+// outcomeCodes 已知的错误码 (extensions.code) 与领取结果，带前缀的错误码 (例如 ACTIVITY_ALREADY_COMPLETED) 按后缀匹配
+var outcomeCodes = map[string]Outcome{
+	"ALREADY_COMPLETED": OutcomeAlreadyCompleted,
+	"ALREADY_VERIFIED":  OutcomeAlreadyCompleted,
+	"ALREADY_CLAIMED":   OutcomeAlreadyCompleted,
+	"COOLDOWN":          OutcomeOnCooldown,
+	"ON_COOLDOWN":       OutcomeOnCooldown,
+	"TOO_EARLY":         OutcomeOnCooldown,
+	"NOT_ELIGIBLE":      OutcomeIneligible,
+	"INELIGIBLE":        OutcomeIneligible,
+}
+
+// outcomePhrases 错误码无法识别时，按错误信息中的固定短语判断领取结果
+var outcomePhrases = []struct {
+	phrase  string
+	outcome Outcome
+}{
+	{"already completed", OutcomeAlreadyCompleted},
+	{"already verified", OutcomeAlreadyCompleted},
+	{"already claimed", OutcomeAlreadyCompleted},
+	{"cooldown", OutcomeOnCooldown},
+	{"too early", OutcomeOnCooldown},
+	{"not yet available", OutcomeOnCooldown},
+	{"not eligible", OutcomeIneligible},
+}
+
+// ClassifyGraphQLError 将 Deform 的错误映射为领取结果：优先按错误码判断，其次按错误信息中的固定短语，都无法识别时视为失败
+func ClassifyGraphQLError(gqlErr GraphQLError) *ActivityError {
+	code, _ := gqlErr.Extensions["code"].(string)
+	activityErr := &ActivityError{
+		Outcome: OutcomeFailed,
+		Code:    code,
+		Message: "GraphQL错误: " + gqlErr.Message,
+	}
+
+	outcome, ok := outcomeForCode(code)
+	if !ok {
+		message := strings.ToLower(gqlErr.Message)
+		for _, p := range outcomePhrases {
+			if strings.Contains(message, p.phrase) {
+				outcome = p.outcome
+				break
+			}
+		}
+	}
+	if outcome != "" {
+		activityErr.Outcome = outcome
+	}
+	if activityErr.Outcome == OutcomeOnCooldown {
+		activityErr.NextEligibleAt = nextEligibleAt(gqlErr.Extensions)
+	}
+	return activityErr
+}
+
+// outcomeForCode 按错误码查找领取结果
+func outcomeForCode(code string) (Outcome, bool) {
+	code = strings.ToUpper(code)
+	if outcome, ok := outcomeCodes[code]; ok {
+		return outcome, true
+	}
+	for known, outcome := range outcomeCodes {
+		if strings.HasSuffix(code, "_"+known) {
+			return outcome, true
+		}
+	}
+	return "", false
+}
+
+// nextEligibleAt 从错误扩展字段中读取下次可领取时间
+func nextEligibleAt(extensions map[string]interface{}) time.Time {
+	for _, key := range []string{"nextEligibleAt", "availableAt", "cooldownEndsAt"} {
+		if value, ok := extensions[key].(string); ok {
+			if t, err := time.Parse(time.RFC3339, value); err == nil {
+				return t
+			}
+		}
+	}
+	switch value := extensions["retryAfter"].(type) {
+	case float64:
+		return time.Now().Add(time.Duration(value) * time.Second)
+	case string:
+		if seconds, err := strconv.Atoi(value); err == nil {
+			return time.Now().Add(time.Duration(seconds) * time.Second)
+		}
+	}
+	return time.Time{}
+}
+
+func containsAny(s string, substrs ...string) bool {
+	for _, substr := range substrs {
+		if strings.Contains(s, substr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package deform
+
+import (
+	"fmt"
+	"testing"
+)
+
+func gqlError(code, message string) GraphQLError {
+	err := GraphQLError{Message: message, Extensions: map[string]interface{}{}}
+	if code != "" {
+		err.Extensions["code"] = code
+	}
+	return err
+}
+
+func TestClassifyGraphQLError(t *testing.T) {
+	tests := []struct {
+		code    string
+		message string
+		want    Outcome
+	}{
+		{"ACTIVITY_ALREADY_COMPLETED", "Activity already completed", OutcomeAlreadyCompleted},
+		{"ALREADY_CLAIMED", "", OutcomeAlreadyCompleted},
+		{"ACTIVITY_COOLDOWN", "Activity on cooldown", OutcomeOnCooldown},
+		{"NOT_ELIGIBLE", "", OutcomeIneligible},
+		{"", "You are not eligible for this activity", OutcomeIneligible},
+		{"", "Activity is in cooldown", OutcomeOnCooldown},
+		// 错误码优先于错误信息
+		{"ACTIVITY_COOLDOWN", "Activity already completed today", OutcomeOnCooldown},
+		// 宽泛的词不再视为不符合条件
+		{"FORBIDDEN", "Forbidden", OutcomeFailed},
+		{"", "requirement check failed", OutcomeFailed},
+		{"", "something went wrong", OutcomeFailed},
+	}
+	for _, tt := range tests {
+		got := ClassifyGraphQLError(gqlError(tt.code, tt.message)).Outcome
+		if got != tt.want {
+			t.Errorf("ClassifyGraphQLError(%q, %q) = %s，期望 %s", tt.code, tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestOutcomeSucceeded(t *testing.T) {
+	for outcome, want := range map[Outcome]bool{
+		OutcomeCompleted:        true,
+		OutcomeAlreadyCompleted: true,
+		OutcomeOnCooldown:       true,
+		OutcomeIneligible:       false,
+		OutcomeFailed:           false,
+	} {
+		if got := outcome.Succeeded(); got != want {
+			t.Errorf("%s.Succeeded() = %v，期望 %v", outcome, got, want)
+		}
+	}
+}
+
+func TestIsAuthError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&APIError{StatusCode: 401, Body: "Unauthorized"}, true},
+		{fmt.Errorf("领取失败: %w", &APIError{StatusCode: 401}), true},
+		{&APIError{StatusCode: 500, Body: "unauthorized"}, false},
+		{ClassifyGraphQLError(gqlError("UNAUTHENTICATED", "Unauthorized")), true},
+		{ClassifyGraphQLError(gqlError("", "jwt expired")), true},
+		{ClassifyGraphQLError(gqlError("FORBIDDEN", "Forbidden")), false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsAuthError(tt.err); got != tt.want {
+			t.Errorf("IsAuthError(%v) = %v，期望 %v", tt.err, got, tt.want)
+		}
+	}
+}
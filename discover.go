@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"blockmesh/config"
+	"blockmesh/runner"
+)
+
+// runDiscover 列出活动下的全部任务，并标出是否已在配置中启用
+func runDiscover(ctx context.Context, cfg *config.Config, backend runner.CampaignBackend, w io.Writer) error {
+	if cfg.Campaign.ID == "" {
+		return fmt.Errorf("未配置 campaign.id，无法查询活动列表")
+	}
+	proxy := ""
+	if len(cfg.Accounts) > 0 {
+		proxy = cfg.Accounts[0].Proxy
+	}
+	campaign, err := backend.QueryCampaignActivities(ctx, cfg.Campaign.ID, proxy)
+	if err != nil {
+		return err
+	}
+
+	configured := make(map[string]bool)
+	for _, activity := range cfg.Campaign.Activities {
+		configured[activity.ID] = !activity.Disabled
+	}
+	if len(cfg.Campaign.Activities) == 0 {
+		for _, activity := range config.DefaultActivities {
+			configured[activity.ID] = true
+		}
+	}
+
+	fmt.Fprintf(w, "活动: %s (%s)\n", valueOrDash(campaign.Name), campaign.ID)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "活动ID\t名称\t类型\t配置")
+	for _, activity := range campaign.Activities {
+		state := "未配置"
+		if enabled, ok := configured[activity.ID]; ok {
+			state = "已启用"
+			if !enabled {
+				state = "已停用"
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", activity.ID, valueOrDash(activity.Title), valueOrDash(activity.Type), state)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"blockmesh/config"
+	"blockmesh/runner"
+	"blockmesh/signer"
+	"blockmesh/siwe"
+)
+
+// 预演模式下代替真实凭据的占位符
+const (
+	dryRunNonce         = "dryrunnonce00000"
+	dryRunPrivyToken    = "<privy-access-token>"
+	dryRunIdentityToken = "<privy-identity-token>"
+	dryRunDeformToken   = "<deform-token>"
+)
+
+// DryRunOptions 预演模式选项
+type DryRunOptions struct {
+	// VerifySignatures 在本地校验生成的签名
+	VerifySignatures bool
+	// FetchNonce 允许调用只读的 Privy 初始化接口获取真实 nonce
+	FetchNonce bool
+}
+
+// redactedHeaders 输出时需要隐藏的请求头
+var redactedHeaders = map[string]bool{
+	"Authorization":  true,
+	"Privy-Id-Token": true,
+}
+
+// redactedFields 输出时需要隐藏的请求体字段
+var redactedFields = map[string]bool{
+	"externalAuthToken": true,
+	"refresh_token":     true,
+	"token":             true,
+}
+
+// runDryRun 预演每个账户的完整流程：派生地址、生成签名并打印将要发送的请求，不领取任何奖励
+func runDryRun(ctx context.Context, cfg *config.Config, clients runner.ClientOptions, filter runner.AccountFilter, opts DryRunOptions, w io.Writer) error {
+	activities := cfg.Campaign.EnabledActivities()
+	privyClient, deformClient := runner.NewClients(cfg.Endpoints, clients)
+	for i, account := range cfg.Accounts {
+		if filter != nil && !filter(i, account) {
+			continue
+		}
+		fmt.Fprintf(w, "########## 账户 #%d%s (代理: %s) ##########\n", i+1, account.DisplaySuffix(), runner.RedactProxy(account.Proxy))
+
+		address, err := signer.GetAddressFromPrivateKey(account.PrivateKey)
+		if err != nil {
+			fmt.Fprintf(w, "获取地址失败: %v\n\n", err)
+			continue
+		}
+		fmt.Fprintf(w, "地址: %s\n\n", address)
+
+		req, err := privyClient.NewInitRequest(ctx, address)
+		if err != nil {
+			return err
+		}
+		dumpRequest(w, "InitPrivyAuth", req)
+
+		nonce := dryRunNonce
+		if opts.FetchNonce {
+			initResponse, err := privyClient.InitAuth(ctx, address, account.Proxy)
+			if err != nil {
+				fmt.Fprintf(w, "获取 Nonce 失败: %v\n\n", err)
+				continue
+			}
+			nonce = initResponse.Nonce
+		}
+
+		authRequest, err := privyClient.BuildAuthenticateRequest(account.PrivateKey, address, nonce, siwe.GetCurrentTimeInISO8601(), signer.PurposeDryRun)
+		if err != nil {
+			fmt.Fprintf(w, "%v\n\n", err)
+			continue
+		}
+		// 使用真实 nonce 时签名在 nonce 过期前可直接用于登录，只输出首尾部分
+		signature := authRequest.Signature
+		if opts.FetchNonce {
+			signature = maskSignature(signature)
+		}
+		fmt.Fprintf(w, "SIWE 消息:\n%s\n\n签名: %s\n", authRequest.Message, signature)
+		if opts.VerifySignatures {
+			if err := siwe.VerifyEIP4361Signature(authRequest.Message, authRequest.Signature, address); err != nil {
+				fmt.Fprintf(w, "本地签名校验失败: %v\n", err)
+			} else {
+				fmt.Fprintf(w, "本地签名校验通过\n")
+			}
+		}
+		fmt.Fprintln(w)
+
+		printed := authRequest
+		printed.Signature = signature
+		if req, err = privyClient.NewAuthenticateRequest(ctx, printed); err != nil {
+			return err
+		}
+		dumpRequest(w, "AuthenticateWithPrivy", req)
+
+		if req, err = deformClient.NewLoginRequest(ctx, dryRunPrivyToken); err != nil {
+			return err
+		}
+		dumpRequest(w, "DeformLoginRequest", req)
+
+		for _, activity := range activities {
+			if req, err = deformClient.NewVerifyActivityRequest(ctx, activity.ID, dryRunDeformToken, dryRunIdentityToken); err != nil {
+				return err
+			}
+			dumpRequest(w, "VerifyActivity "+cfg.Campaign.ActivityName(activity.ID), req)
+		}
+	}
+	return nil
+}
+
+// maskSignature 只保留签名前后各 6 位十六进制字符
+func maskSignature(signature string) string {
+	hex := strings.TrimPrefix(signature, "0x")
+	if len(hex) <= 12 {
+		return "<redacted>"
+	}
+	return "0x" + hex[:6] + "…" + hex[len(hex)-6:]
+}
+
+// dumpRequest 打印请求的方法、地址、请求头与请求体，并隐藏敏感信息
+func dumpRequest(w io.Writer, stage string, req *http.Request) {
+	fmt.Fprintf(w, "===== %s =====\n%s %s\n", stage, req.Method, req.URL)
+
+	keys := make([]string, 0, len(req.Header))
+	for key := range req.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := strings.Join(req.Header[key], ", ")
+		if redactedHeaders[key] {
+			value = "<redacted>"
+		}
+		fmt.Fprintf(w, "%s: %s\n", key, value)
+	}
+
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			fmt.Fprintf(w, "\n%s\n", redactJSON(data))
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+// redactJSON 隐藏 JSON 请求体中的敏感字段并格式化输出
+func redactJSON(data []byte) []byte {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return data
+	}
+	redactFields(v, redactedFields)
+
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return data
+	}
+	return bytes.TrimSpace(out.Bytes())
+}
+
+// redactFields 将 JSON 中指定字段的字符串值替换为 <redacted>
+func redactFields(v interface{}, fields map[string]bool) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if _, ok := field.(string); ok && fields[key] {
+				value[key] = "<redacted>"
+				continue
+			}
+			redactFields(field, fields)
+		}
+	case []interface{}:
+		for _, item := range value {
+			redactFields(item, fields)
+		}
+	}
+}
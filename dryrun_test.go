@@ -0,0 +1,13 @@
+package main
+
+import "testing"
+
+func TestMaskSignature(t *testing.T) {
+	signature := "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef1b"
+	if got, want := maskSignature(signature), "0x123456…cdef1b"; got != want {
+		t.Errorf("maskSignature = %q，期望 %q", got, want)
+	}
+	if got := maskSignature("0xabcd"); got != "<redacted>" {
+		t.Errorf("过短的签名应整体隐藏，实际 %q", got)
+	}
+}
@@ -0,0 +1,156 @@
+package fakeserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"blockmesh/siwe"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const testKey = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+
+// post 发送 JSON 请求，返回状态码、响应头与解析后的响应
+func post(t *testing.T, url string, body interface{}) (int, http.Header, map[string]interface{}) {
+	t.Helper()
+	data, _ := json.Marshal(body)
+	resp, err := http.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var result map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	return resp.StatusCode, resp.Header, result
+}
+
+// initNonce 获取 nonce，返回 nonce、过期时间与响应头中的服务器时间
+func initNonce(t *testing.T, ts *httptest.Server, address string) (string, time.Time, time.Time) {
+	t.Helper()
+	status, header, result := post(t, ts.URL+"/api/v1/siwe/init", map[string]string{"address": address})
+	if status != http.StatusOK {
+		t.Fatalf("获取 nonce 返回 %d: %v", status, result)
+	}
+	expiresAt, err := time.Parse(time.RFC3339Nano, result["expires_at"].(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	date, err := http.ParseTime(header.Get("Date"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result["nonce"].(string), expiresAt, date
+}
+
+// authenticate 以 issuedAt 为 Issued At 签名并认证，返回状态码与响应
+func authenticate(t *testing.T, ts *httptest.Server, address, nonce string, issuedAt time.Time) (int, map[string]interface{}) {
+	t.Helper()
+	key, err := crypto.HexToECDSA(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := siwe.FormatEIP4361Message("example.com", address, "", "https://example.com", "1", "1", nonce, siwe.FormatTime(issuedAt), nil)
+	signature, err := crypto.Sign(siwe.HashMessage(message), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, _, result := post(t, ts.URL+"/api/v1/siwe/authenticate", map[string]string{
+		"message":   message,
+		"signature": hexutil.Encode(signature),
+	})
+	return status, result
+}
+
+func testAddress(t *testing.T) string {
+	key, err := crypto.HexToECDSA(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return crypto.PubkeyToAddress(key.PublicKey).Hex()
+}
+
+func TestNonceExpiry(t *testing.T) {
+	server := New(Options{NonceTTL: 60})
+	ts := httptest.NewServer(server.Handler())
+	defer ts.Close()
+	address := testAddress(t)
+
+	nonce, expiresAt, date := initNonce(t, ts, address)
+	if ttl := expiresAt.Sub(date); ttl < 59*time.Second || ttl > 61*time.Second {
+		t.Errorf("nonce 有效期为 %s，期望 60 秒", ttl)
+	}
+	if status, result := authenticate(t, ts, address, nonce, time.Now()); status != http.StatusOK {
+		t.Fatalf("有效期内认证应成功，返回 %d: %v", status, result)
+	}
+
+	nonce, _, _ = initNonce(t, ts, address)
+	server.SetClock(func() time.Time { return time.Now().Add(2 * time.Minute) })
+	status, result := authenticate(t, ts, address, nonce, time.Now())
+	if status != http.StatusUnauthorized || result["error"] != "Expired nonce" {
+		t.Errorf("nonce 过期后应返回 Expired nonce，实际 %d: %v", status, result)
+	}
+}
+
+func TestFailLogin(t *testing.T) {
+	server := New(Options{FailLogin: 1})
+	ts := httptest.NewServer(server.Handler())
+	defer ts.Close()
+	address := testAddress(t)
+
+	nonce, _, _ := initNonce(t, ts, address)
+	status, result := authenticate(t, ts, address, nonce, time.Now())
+	if status != http.StatusOK {
+		t.Fatalf("认证返回 %d: %v", status, result)
+	}
+	login := map[string]interface{}{
+		"operationName": "UserLogin",
+		"variables":     map[string]interface{}{"data": map[string]interface{}{"externalAuthToken": result["token"]}},
+	}
+
+	if status, _, result := post(t, ts.URL+"/", login); status != http.StatusInternalServerError {
+		t.Errorf("第一次登录应返回 500，实际 %d: %v", status, result)
+	}
+	status, _, result = post(t, ts.URL+"/", login)
+	data, _ := result["data"].(map[string]interface{})
+	if status != http.StatusOK || data["userLogin"] == nil {
+		t.Errorf("第二次登录应成功，实际 %d: %v", status, result)
+	}
+	if calls := server.Calls("UserLogin"); calls != 2 {
+		t.Errorf("UserLogin 调用 %d 次，期望 2 次", calls)
+	}
+}
+
+func TestClockOffset(t *testing.T) {
+	var opts Options
+	if err := json.Unmarshal([]byte(`{"clock_offset": -3600, "issued_at_tolerance": 60}`), &opts); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(New(opts).Handler())
+	defer ts.Close()
+	address := testAddress(t)
+
+	nonce, expiresAt, date := initNonce(t, ts, address)
+	if offset := time.Until(date); offset > -59*time.Minute || offset < -61*time.Minute {
+		t.Errorf("服务器时间与本地相差 %s，期望约 -1h", offset)
+	}
+	if ttl := expiresAt.Sub(date); ttl < DefaultNonceTTL-time.Second || ttl > DefaultNonceTTL+time.Second {
+		t.Errorf("nonce 有效期为 %s，期望 %s", ttl, DefaultNonceTTL)
+	}
+
+	status, result := authenticate(t, ts, address, nonce, time.Now())
+	if status != http.StatusUnauthorized || result["error"] != "Invalid issued at" {
+		t.Errorf("Issued At 按本地时间填写时应被拒绝，实际 %d: %v", status, result)
+	}
+	nonce, _, date = initNonce(t, ts, address)
+	if status, result := authenticate(t, ts, address, nonce, date); status != http.StatusOK {
+		t.Errorf("Issued At 按服务器时间填写时应成功，实际 %d: %v", status, result)
+	}
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"strings"
+)
+
+// listFlag 可重复、可用逗号分隔的字符串参数
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
@@ -4,11 +4,16 @@ go 1.23.0
 
 toolchain go1.23.4
 
-require github.com/ethereum/go-ethereum v1.15.5
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/ethereum/go-ethereum v1.15.5
+	golang.org/x/crypto v0.33.0
+	golang.org/x/term v0.29.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.0.1 // indirect
 	github.com/holiman/uint256 v1.3.2 // indirect
-	golang.org/x/crypto v0.33.0 // indirect
 	golang.org/x/sys v0.31.0 // indirect
 )
@@ -0,0 +1,173 @@
+// Package httpclient 创建访问 Privy 与 Deform 的 HTTP 客户端，按代理与主机复用连接，
+// 并在 context 中记录请求所属的账户与阶段，供按阶段超时与录制/回放模式使用。
+// 超时、录制/回放与连接池都保存在 Client 中，不同的 Client 互不影响。
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type accountContextKey struct{}
+type stageContextKey struct{}
+
+// WithAccount 在 context 中记录当前处理的账户地址
+func WithAccount(ctx context.Context, address string) context.Context {
+	return context.WithValue(ctx, accountContextKey{}, address)
+}
+
+// WithStage 在 context 中记录当前请求所属的阶段
+func WithStage(ctx context.Context, stage string) context.Context {
+	return context.WithValue(ctx, stageContextKey{}, stage)
+}
+
+// Labels 返回请求所属的账户地址 (小写) 与阶段，未记录时分别为 unknown 与 request
+func Labels(ctx context.Context) (string, string) {
+	address, _ := ctx.Value(accountContextKey{}).(string)
+	if address == "" {
+		address = "unknown"
+	}
+	stage, _ := ctx.Value(stageContextKey{}).(string)
+	if stage == "" {
+		stage = "request"
+	}
+	return strings.ToLower(address), stage
+}
+
+// DefaultTimeout 未配置时的请求超时
+const DefaultTimeout = 10 * time.Second
+
+// 连接池参数，同一代理访问同一主机时复用 TCP/TLS 连接
+const (
+	dialTimeout         = 10 * time.Second
+	keepAlive           = 30 * time.Second
+	maxIdleConns        = 100
+	maxIdleConnsPerHost = 10
+	idleConnTimeout     = 90 * time.Second
+	tlsHandshakeTimeout = 10 * time.Second
+)
+
+// Wrapper 包装传输层的函数，用于录制或回放请求
+type Wrapper func(base http.RoundTripper) http.RoundTripper
+
+// Timeouts 默认超时与按阶段覆盖的超时。Default 不大于 0 时使用 DefaultTimeout，
+// 阶段名带后缀时 (如 verify-activity-<活动ID>) 也会匹配其前缀对应的设置
+type Timeouts struct {
+	Default time.Duration
+	Stages  map[string]time.Duration
+}
+
+// For 返回阶段对应的请求超时
+func (t Timeouts) For(stage string) time.Duration {
+	if d, ok := t.Stages[stage]; ok {
+		return d
+	}
+	for name, d := range t.Stages {
+		if strings.HasPrefix(stage, name+"-") {
+			return d
+		}
+	}
+	if t.Default <= 0 {
+		return DefaultTimeout
+	}
+	return t.Default
+}
+
+// Pool 按代理与主机缓存的传输层，可在多个 Client 之间共享，可并发使用
+type Pool struct {
+	mu         sync.Mutex
+	transports map[string]*http.Transport
+}
+
+// NewPool 创建空的连接池
+func NewPool() *Pool {
+	return &Pool{transports: make(map[string]*http.Transport)}
+}
+
+// transport 返回代理与主机对应的传输层，不存在时创建
+func (p *Pool) transport(proxyURL, host string) (*http.Transport, error) {
+	key := proxyURL + "|" + strings.ToLower(host)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if transport, ok := p.transports[key]; ok {
+		return transport, nil
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if proxyURL != "" {
+		parsed, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("解析代理URL失败: %v", err)
+		}
+		proxy = http.ProxyURL(parsed)
+	}
+	transport := &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   dialTimeout,
+			KeepAlive: keepAlive,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          maxIdleConns,
+		MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+		IdleConnTimeout:       idleConnTimeout,
+		TLSHandshakeTimeout:   tlsHandshakeTimeout,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	p.transports[key] = transport
+	return transport, nil
+}
+
+// CloseIdleConnections 关闭所有缓存传输层中的空闲连接并清空缓存，传输层在下次请求时按需重新创建，
+// 重新加载配置后已移除的代理与主机不会继续占用缓存
+func (p *Pool) CloseIdleConnections() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, transport := range p.transports {
+		transport.CloseIdleConnections()
+		delete(p.transports, key)
+	}
+}
+
+// Client 按请求所属的阶段设置超时、从连接池取传输层的客户端工厂，创建后不再修改
+type Client struct {
+	pool     *Pool
+	timeouts Timeouts
+	wrapper  Wrapper
+}
+
+// New 创建客户端工厂。pool 为 nil 时新建连接池，wrapper 为 nil 时直接访问网络
+func New(pool *Pool, timeouts Timeouts, wrapper Wrapper) *Client {
+	if pool == nil {
+		pool = NewPool()
+	}
+	return &Client{pool: pool, timeouts: timeouts, wrapper: wrapper}
+}
+
+// Timeout 返回阶段对应的请求超时
+func (c *Client) Timeout(stage string) time.Duration {
+	return c.timeouts.For(stage)
+}
+
+// ForRequest 返回发送 req 使用的客户端：按代理与主机复用传输层，超时取决于请求所属的阶段
+func (c *Client) ForRequest(req *http.Request, proxyURL string) (*http.Client, error) {
+	transport, err := c.pool.transport(proxyURL, req.URL.Host)
+	if err != nil {
+		return nil, err
+	}
+	_, stage := Labels(req.Context())
+	var roundTripper http.RoundTripper = transport
+	if c.wrapper != nil {
+		roundTripper = c.wrapper(transport)
+	}
+	return &http.Client{
+		Transport: roundTripper,
+		Timeout:   c.Timeout(stage),
+	}, nil
+}
@@ -0,0 +1,79 @@
+package httpclient
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPoolReusesTransportUntilClosed(t *testing.T) {
+	pool := NewPool()
+	first, err := pool.transport("http://127.0.0.1:8080", "Example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same, _ := pool.transport("http://127.0.0.1:8080", "example.com"); same != first {
+		t.Errorf("相同代理与主机应复用传输层")
+	}
+	if other, _ := pool.transport("", "example.com"); other == first {
+		t.Errorf("不同代理不应复用传输层")
+	}
+
+	pool.CloseIdleConnections()
+	if cached := len(pool.transports); cached != 0 {
+		t.Fatalf("关闭空闲连接后仍缓存 %d 个传输层", cached)
+	}
+	if rebuilt, _ := pool.transport("http://127.0.0.1:8080", "example.com"); rebuilt == first {
+		t.Errorf("清空缓存后应重新创建传输层")
+	}
+}
+
+func TestTimeouts(t *testing.T) {
+	timeouts := Timeouts{Default: 5 * time.Second, Stages: map[string]time.Duration{"verify-activity": time.Second}}
+	tests := map[string]time.Duration{
+		"verify-activity":     time.Second,
+		"verify-activity-abc": time.Second,
+		"privy-init":          5 * time.Second,
+	}
+	for stage, want := range tests {
+		if got := timeouts.For(stage); got != want {
+			t.Errorf("%s: 超时为 %s，期望 %s", stage, got, want)
+		}
+	}
+	if got := (Timeouts{}).For("privy-init"); got != DefaultTimeout {
+		t.Errorf("未配置时超时为 %s，期望 %s", got, DefaultTimeout)
+	}
+}
+
+// roundTripperFunc 把函数转换为 http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestClientsSharePoolWithOwnSettings(t *testing.T) {
+	req, err := http.NewRequest("GET", "http://example.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := NewPool()
+	wrapped := roundTripperFunc(func(*http.Request) (*http.Response, error) { return nil, nil })
+	recording := New(pool, Timeouts{Default: time.Second}, func(http.RoundTripper) http.RoundTripper { return wrapped })
+	direct := New(pool, Timeouts{}, nil)
+
+	client, err := recording.ForRequest(req, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := client.Transport.(roundTripperFunc); !ok || client.Timeout != time.Second {
+		t.Errorf("录制客户端应使用包装后的传输层与自己的超时: %T %s", client.Transport, client.Timeout)
+	}
+	if client, err = direct.ForRequest(req, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := client.Transport.(*http.Transport); !ok || client.Timeout != DefaultTimeout {
+		t.Errorf("另一个客户端不应受录制设置影响: %T %s", client.Transport, client.Timeout)
+	}
+	if len(pool.transports) != 1 {
+		t.Errorf("两个客户端应共享连接池中的传输层，实际缓存 %d 个", len(pool.transports))
+	}
+}
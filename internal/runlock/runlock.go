@@ -0,0 +1,152 @@
+// Package runlock 提供进程间互斥的运行锁，防止多个实例同时执行同一批账户。
+//
+// 锁文件中记录持有者的 PID 与开始时间。Unix 系统使用 flock，进程退出后锁自动释放；
+// 其它系统以独占方式创建锁文件，持有者进程已不存在时视为过期锁并清除。
+package runlock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// DefaultFile 默认锁文件路径
+const DefaultFile = "coinshift.lock"
+
+// retryInterval 等待锁时的重试间隔
+const retryInterval = 500 * time.Millisecond
+
+// Info 锁文件中记录的持有者信息
+type Info struct {
+	PID       int       `json:"pid"`
+	Command   string    `json:"command,omitempty"`
+	Hostname  string    `json:"hostname,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+func (i *Info) String() string {
+	if i == nil || i.PID == 0 {
+		return "未知进程"
+	}
+	s := fmt.Sprintf("PID %d", i.PID)
+	if i.Command != "" {
+		s += " (" + i.Command + ")"
+	}
+	if !i.StartedAt.IsZero() {
+		s += "，开始于 " + i.StartedAt.Local().Format("2006-01-02 15:04:05")
+	}
+	return s
+}
+
+// HeldError 锁已被其它进程持有
+type HeldError struct {
+	Path   string
+	Holder *Info
+}
+
+func (e *HeldError) Error() string {
+	return fmt.Sprintf("已有实例在运行 (%s)，锁文件 %s", e.Holder, e.Path)
+}
+
+// IsHeld 判断错误是否为锁已被持有
+func IsHeld(err error) bool {
+	var held *HeldError
+	return errors.As(err, &held)
+}
+
+// Lock 已获取的运行锁
+type Lock struct {
+	path  string
+	file  *os.File
+	stale *Info
+}
+
+// Path 返回锁文件路径
+func (l *Lock) Path() string {
+	return l.path
+}
+
+// Stale 返回获取锁时清除的过期锁的持有者，没有过期锁时返回 nil
+func (l *Lock) Stale() *Info {
+	return l.stale
+}
+
+// Acquire 尝试获取运行锁，已被其它进程持有时立即返回 *HeldError
+func Acquire(path, command string) (*Lock, error) {
+	if path == "" {
+		path = DefaultFile
+	}
+	info := &Info{PID: os.Getpid(), Command: command, StartedAt: time.Now()}
+	info.Hostname, _ = os.Hostname()
+	return acquire(path, info)
+}
+
+// Wait 获取运行锁，被其它进程持有时每隔一段时间重试，直到获取成功、超过 timeout 或 ctx 取消。
+// timeout 为 0 时等同于 Acquire
+func Wait(ctx context.Context, path, command string, timeout time.Duration) (*Lock, error) {
+	lock, err := Acquire(path, command)
+	if err == nil || !IsHeld(err) || timeout <= 0 {
+		return lock, err
+	}
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(retryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline.C:
+			return nil, err
+		case <-ticker.C:
+		}
+		lock, err = Acquire(path, command)
+		if err == nil || !IsHeld(err) {
+			return lock, err
+		}
+	}
+}
+
+// Release 删除锁文件并释放锁
+func (l *Lock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	err := l.release()
+	l.file = nil
+	if err != nil {
+		return fmt.Errorf("释放运行锁失败: %v", err)
+	}
+	return nil
+}
+
+// writeInfo 把持有者信息写入锁文件
+func writeInfo(file *os.File, info *Info) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	if err := file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := file.WriteAt(append(data, '\n'), 0); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// readInfo 读取锁文件中的持有者信息，文件为空或已损坏时返回 nil
+func readInfo(path string) *Info {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var info Info
+	if err := json.Unmarshal(data, &info); err != nil || info.PID == 0 {
+		return nil
+	}
+	return &info
+}
@@ -0,0 +1,74 @@
+//go:build !unix || aix || solaris
+
+package runlock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+// staleUnreadable 锁文件内容无法解析且超过该时长未修改时视为过期 (持有者在写入前崩溃)
+const staleUnreadable = 10 * time.Second
+
+// acquire 不支持 flock 的系统以独占方式创建锁文件，持有者进程已不存在时清除过期锁后重试
+func acquire(path string, info *Info) (*Lock, error) {
+	var stale *Info
+	for attempt := 0; ; attempt++ {
+		file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			if err := writeInfo(file, info); err != nil {
+				file.Close()
+				os.Remove(path)
+				return nil, fmt.Errorf("写入锁文件失败: %v", err)
+			}
+			return &Lock{path: path, file: file, stale: stale}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("创建锁文件失败: %v", err)
+		}
+
+		holder := readInfo(path)
+		if attempt > 0 || !isStale(path, holder) {
+			return nil, &HeldError{Path: path, Holder: holder}
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("清除过期锁文件失败: %v", err)
+		}
+		stale = holder
+	}
+}
+
+// isStale 判断锁文件是否已过期：持有者进程不存在，或内容无法解析且长时间未修改
+func isStale(path string, holder *Info) bool {
+	if holder == nil {
+		stat, err := os.Stat(path)
+		return err == nil && time.Since(stat.ModTime()) > staleUnreadable
+	}
+	if holder.PID == os.Getpid() {
+		return false
+	}
+	// Windows 上进程不存在时 FindProcess 返回错误，其它系统需要发送信号 0 检查
+	process, err := os.FindProcess(holder.PID)
+	if err != nil {
+		return true
+	}
+	defer process.Release()
+	if runtime.GOOS == "windows" {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	return errors.Is(err, os.ErrProcessDone) || errors.Is(err, syscall.ESRCH)
+}
+
+// release 关闭并删除锁文件
+func (l *Lock) release() error {
+	closeErr := l.file.Close()
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return closeErr
+}
@@ -0,0 +1,89 @@
+package runlock
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquireIsExclusive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultFile)
+	lock, err := Acquire(path, "run")
+	if err != nil {
+		t.Fatalf("获取锁失败: %v", err)
+	}
+	if lock.Stale() != nil {
+		t.Errorf("新建的锁不应报告过期锁")
+	}
+
+	_, err = Acquire(path, "status")
+	var held *HeldError
+	if !errors.As(err, &held) {
+		t.Fatalf("锁被持有时应返回 *HeldError，实际 %v", err)
+	}
+	if held.Holder == nil || held.Holder.PID != os.Getpid() || held.Holder.Command != "run" {
+		t.Errorf("持有者信息不正确: %+v", held.Holder)
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("释放锁失败: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("释放后应删除锁文件")
+	}
+	again, err := Acquire(path, "status")
+	if err != nil {
+		t.Fatalf("释放后应能重新获取锁: %v", err)
+	}
+	again.Release()
+}
+
+func TestAcquireClearsStaleLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultFile)
+	// 崩溃进程残留的锁文件：记录的进程不存在，也没有持有 flock
+	if err := os.WriteFile(path, []byte(`{"pid":999999,"command":"run"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	lock, err := Acquire(path, "run")
+	if err != nil {
+		t.Fatalf("残留锁文件不应阻止获取锁: %v", err)
+	}
+	defer lock.Release()
+	if stale := lock.Stale(); stale == nil || stale.PID != 999999 {
+		t.Errorf("应报告清除的过期锁，实际 %v", stale)
+	}
+	if info := readInfo(path); info == nil || info.PID != os.Getpid() {
+		t.Errorf("锁文件应记录当前进程，实际 %v", info)
+	}
+}
+
+func TestWait(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultFile)
+	lock, err := Acquire(path, "daemon")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Wait(context.Background(), path, "run", 100*time.Millisecond); !IsHeld(err) {
+		t.Errorf("等待超时应返回 *HeldError，实际 %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Wait(ctx, path, "run", time.Minute); !errors.Is(err, context.Canceled) {
+		t.Errorf("ctx 取消时应返回 context.Canceled，实际 %v", err)
+	}
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		lock.Release()
+	}()
+	waited, err := Wait(context.Background(), path, "run", 5*time.Second)
+	if err != nil {
+		t.Fatalf("持有者释放后应获取到锁: %v", err)
+	}
+	waited.Release()
+}
@@ -0,0 +1,60 @@
+//go:build unix && !aix && !solaris
+
+package runlock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// acquire 打开锁文件并加 flock 排它锁。锁由内核持有，进程退出 (包括崩溃) 后自动释放，
+// 因此能加锁时文件中残留的持有者信息一定已过期
+func acquire(path string, info *Info) (*Lock, error) {
+	for {
+		file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("打开锁文件失败: %v", err)
+		}
+		if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+			file.Close()
+			if errors.Is(err, syscall.EWOULDBLOCK) {
+				return nil, &HeldError{Path: path, Holder: readInfo(path)}
+			}
+			return nil, fmt.Errorf("获取运行锁失败: %v", err)
+		}
+
+		// 加锁前文件可能已被上一个持有者删除，此时锁住的是旧文件，需要重新打开
+		opened, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("获取运行锁失败: %v", err)
+		}
+		current, err := os.Stat(path)
+		if err != nil || !os.SameFile(opened, current) {
+			file.Close()
+			continue
+		}
+
+		lock := &Lock{path: path, file: file}
+		if stale := readInfo(path); stale != nil && stale.PID != info.PID {
+			lock.stale = stale
+		}
+		if err := writeInfo(file, info); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("写入锁文件失败: %v", err)
+		}
+		return lock, nil
+	}
+}
+
+// release 先删除锁文件再解锁，等待中的进程会发现文件已被替换并重新打开
+func (l *Lock) release() error {
+	removeErr := os.Remove(l.path)
+	closeErr := l.file.Close()
+	if removeErr != nil && !errors.Is(removeErr, os.ErrNotExist) {
+		return removeErr
+	}
+	return closeErr
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"blockmesh/fakeserver"
+)
+
+// 测试账户私钥 (Hardhat 默认账户)，仅用于假服务
+var testKeys = []string{
+	"0x59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d",
+	"0x5de4111afa1a4b94908f83103eb1f1706367c2e68ca870fc3fb9a804cdab365a",
+}
+
+const testActivity = "304a9530-3720-45c8-a778-fbd3060d5cfd"
+
+// writeTestConfig 在临时目录中写入指向假服务的配置文件，状态文件与锁文件都在同一目录，extra 中的字段覆盖默认值
+func writeTestConfig(t *testing.T, serverURL string, accounts int, extra map[string]interface{}) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	var accountList []map[string]interface{}
+	for i := 0; i < accounts; i++ {
+		accountList = append(accountList, map[string]interface{}{"private_key": testKeys[i]})
+	}
+	cfg := map[string]interface{}{
+		"accounts": accountList,
+		"campaign": map[string]interface{}{
+			"id":         "c1",
+			"activities": []map[string]string{{"id": testActivity}},
+		},
+		"endpoints": map[string]string{
+			"privy_base_url": serverURL + "/api/v1",
+			"deform_api_url": serverURL + "/",
+		},
+		"state_file":   filepath.Join(dir, "state.json"),
+		"ledger_file":  filepath.Join(dir, "rewards.jsonl"),
+		"session_file": filepath.Join(dir, "sessions.json"),
+		"audit_file":   filepath.Join(dir, "signatures.jsonl"),
+		"lock_file":    filepath.Join(dir, "coinshift.lock"),
+	}
+	for key, value := range extra {
+		cfg[key] = value
+	}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("序列化配置失败: %v", err)
+	}
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("写入配置失败: %v", err)
+	}
+	return path, dir
+}
+
+// newTestServer 启动假服务，测试结束时关闭
+func newTestServer(t *testing.T, opts fakeserver.Options) (*fakeserver.Server, *httptest.Server) {
+	t.Helper()
+	server := fakeserver.New(opts)
+	ts := httptest.NewServer(server.Handler())
+	t.Cleanup(ts.Close)
+	return server, ts
+}
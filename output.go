@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"blockmesh/runner"
+)
+
+// 结果输出格式
+const (
+	outputText   = "text"
+	outputNDJSON = "ndjson"
+)
+
+// ndjsonAccount 一个账户的执行结果，活动明细以 ndjsonActivity 单独输出
+type ndjsonAccount struct {
+	Type       string    `json:"type"`
+	Index      int       `json:"index"`
+	Address    string    `json:"address,omitempty"`
+	Label      string    `json:"label,omitempty"`
+	Tags       []string  `json:"tags,omitempty"`
+	Success    bool      `json:"success"`
+	Error      string    `json:"error,omitempty"`
+	Activities int       `json:"activities"`
+	Succeeded  int       `json:"succeeded"`
+	Points     int       `json:"points"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// ndjsonActivity 一个活动的领取结果
+type ndjsonActivity struct {
+	Type    string `json:"type"`
+	Index   int    `json:"index"`
+	Address string `json:"address,omitempty"`
+	Label   string `json:"label,omitempty"`
+	runner.ActivityResult
+}
+
+// ndjsonSummary 本次执行的汇总，始终是最后一行
+type ndjsonSummary struct {
+	Type        string `json:"type"`
+	Accounts    int    `json:"accounts"`
+	Succeeded   int    `json:"succeeded"`
+	Failed      int    `json:"failed"`
+	Interrupted bool   `json:"interrupted"`
+	ExitCode    int    `json:"exit_code"`
+}
+
+// resultStream 以 NDJSON 逐行输出执行结果，每个账户一行，随后每个活动一行
+type resultStream struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+func newResultStream(w io.Writer) *resultStream {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return &resultStream{encoder: encoder}
+}
+
+// WriteAccount 输出一个账户及其全部活动的结果
+func (s *resultStream) WriteAccount(result *runner.AccountResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record := ndjsonAccount{
+		Type:       "account",
+		Index:      result.Index,
+		Address:    result.Address,
+		Label:      result.Label,
+		Tags:       result.Tags,
+		Success:    result.Success,
+		Error:      result.Error,
+		Activities: len(result.Activities),
+		StartedAt:  result.StartedAt,
+		FinishedAt: result.FinishedAt,
+	}
+	for _, activity := range result.Activities {
+		if activity.Success {
+			record.Succeeded++
+		}
+		record.Points += activity.Points
+	}
+	if err := s.encoder.Encode(record); err != nil {
+		return fmt.Errorf("输出账户结果失败: %v", err)
+	}
+	for _, activity := range result.Activities {
+		line := ndjsonActivity{
+			Type:           "activity",
+			Index:          result.Index,
+			Address:        result.Address,
+			Label:          result.Label,
+			ActivityResult: activity,
+		}
+		if err := s.encoder.Encode(line); err != nil {
+			return fmt.Errorf("输出活动结果失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// WriteSummary 输出执行汇总与退出码
+func (s *resultStream) WriteSummary(results []*runner.AccountResult, interrupted bool, exitCode int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summary := ndjsonSummary{
+		Type:        "summary",
+		Accounts:    len(results),
+		Interrupted: interrupted,
+		ExitCode:    exitCode,
+	}
+	for _, result := range results {
+		if result.Success {
+			summary.Succeeded++
+		} else {
+			summary.Failed++
+		}
+	}
+	if err := s.encoder.Encode(summary); err != nil {
+		return fmt.Errorf("输出执行汇总失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"blockmesh/config"
+	"blockmesh/fakeserver"
+	"blockmesh/internal/runlock"
+	"blockmesh/runner"
+)
+
+// captureStdout 执行 fn 并返回其写入标准输出的内容
+func captureStdout(t *testing.T, fn func()) []byte {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "stdout")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = file
+	defer func() { os.Stdout = stdout }()
+	fn()
+	file.Close()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// decodeLines 把 NDJSON 输出解析为 JSON 对象列表
+func decodeLines(t *testing.T, data []byte) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var line map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("不是有效的 JSON 行: %s", scanner.Text())
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestRunNDJSONOutputAndExitCode(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      fakeserver.Options
+		succeeded int
+		exitCode  int
+	}{
+		{"全部成功", fakeserver.Options{}, 2, exitOK},
+		{"部分失败", fakeserver.Options{FailLogin: 1}, 1, exitPartial},
+		{"全部失败", fakeserver.Options{FailLogin: 2}, 0, exitFailure},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, ts := newTestServer(t, test.opts)
+			configPath, _ := writeTestConfig(t, ts.URL, 2, nil)
+			ctx := context.Background()
+
+			var err error
+			output := captureStdout(t, func() {
+				err = runCommand(ctx, []string{"-config", configPath, "-output", "ndjson"})
+			})
+			if code := exitCode(ctx, err); code != test.exitCode {
+				t.Errorf("退出码为 %d，期望 %d (%v)", code, test.exitCode, err)
+			}
+
+			lines := decodeLines(t, output)
+			var indexes []float64
+			for _, line := range lines {
+				if line["type"] == "account" {
+					indexes = append(indexes, line["index"].(float64))
+				}
+				if line["type"] == "activity" && line["index"].(float64) < 1 {
+					t.Errorf("活动行的 index 应从 1 开始: %v", line)
+				}
+			}
+			if fmt.Sprint(indexes) != "[1 2]" {
+				t.Errorf("账户行的 index 为 %v，期望 [1 2]", indexes)
+			}
+			summary := lines[len(lines)-1]
+			if summary["type"] != "summary" || summary["succeeded"] != float64(test.succeeded) || summary["exit_code"] != float64(test.exitCode) {
+				t.Errorf("汇总行不正确: %v", summary)
+			}
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want int
+	}{
+		{"成功", ctx, nil, exitOK},
+		{"参数错误", ctx, usageErrorf("bad flag"), exitUsage},
+		{"配置错误", ctx, loadError(&config.Error{Problems: []string{"x"}}), exitConfig},
+		{"加载配置出错", ctx, loadError(errors.New("口令错误")), exitFailure},
+		{"部分失败", ctx, errRunPartial, exitPartial},
+		{"全部失败", ctx, errRunFailed, exitFailure},
+		{"其它错误", ctx, errors.New("boom"), exitFailure},
+		{"锁被占用", ctx, fmt.Errorf("获取运行锁失败: %w", &runlock.HeldError{Path: "coinshift.lock", Holder: &runlock.Info{PID: 1}}), exitLocked},
+		{"被中断", ctx, errInterrupted, exitInterrupted},
+		{"context 已取消", canceled, errors.New("boom"), exitInterrupted},
+	}
+	for _, test := range tests {
+		if got := exitCode(test.ctx, test.err); got != test.want {
+			t.Errorf("%s: 退出码为 %d，期望 %d", test.name, got, test.want)
+		}
+	}
+
+	ok := &runner.AccountResult{Success: true}
+	failed := &runner.AccountResult{}
+	if err := runOutcome(ctx, []*runner.AccountResult{ok, ok}); err != nil {
+		t.Errorf("全部成功时应返回 nil，实际 %v", err)
+	}
+	if err := runOutcome(ctx, []*runner.AccountResult{ok, failed}); !errors.Is(err, errRunPartial) {
+		t.Errorf("部分失败时应返回 errRunPartial，实际 %v", err)
+	}
+	if err := runOutcome(ctx, []*runner.AccountResult{failed}); !errors.Is(err, errRunFailed) {
+		t.Errorf("全部失败时应返回 errRunFailed，实际 %v", err)
+	}
+	if err := runOutcome(canceled, []*runner.AccountResult{ok}); !errors.Is(err, errInterrupted) {
+		t.Errorf("被中断时应返回 errInterrupted，实际 %v", err)
+	}
+}
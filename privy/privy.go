@@ -0,0 +1,357 @@
+// Package privy 实现 Privy 的 SIWE 登录：获取 nonce、提交签名并换取访问 Token。
+//
+// 登录流程:
+//
+//	client := privy.NewClient("")
+//	init, _ := client.InitAuth(ctx, address, proxy)
+//	request, _ := client.BuildAuthenticateRequest(privateKey, address, init.Nonce, siwe.GetCurrentTimeInISO8601(), signer.PurposeLogin)
+//	response, _ := client.Authenticate(ctx, request, proxy)
+package privy
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"blockmesh/internal/httpclient"
+	"blockmesh/internal/logging"
+	"blockmesh/signer"
+)
+
+// DefaultBaseURL 默认的 Privy 接口地址
+const DefaultBaseURL = "https://auth.privy.io/api/v1"
+
+// Client Privy 接口客户端
+type Client struct {
+	// BaseURL 接口地址，可指向本地假服务
+	BaseURL string
+	// HTTP 发送请求使用的连接池、超时与录制/回放设置
+	HTTP *httpclient.Client
+	// Audit 签名审计日志，为 nil 时不记录
+	Audit *signer.AuditLog
+}
+
+// NewClient 创建客户端，baseURL 为空时使用默认地址；使用默认超时，不记录签名审计日志
+func NewClient(baseURL string) *Client {
+	baseURL = strings.TrimRight(baseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{BaseURL: baseURL, HTTP: httpclient.New(nil, httpclient.Timeouts{}, nil)}
+}
+
+// 请求所属的流程阶段，用于给录制文件命名
+const (
+	StageInit         = "privy-init"
+	StageAuthenticate = "privy-authenticate"
+	StageRefresh      = "privy-refresh"
+)
+
+// Coinshift 活动页面使用的 SIWE 参数
+const (
+	SIWEDomain    = "campaign.coinshift.xyz"
+	SIWEURI       = "https://campaign.coinshift.xyz"
+	SIWEStatement = "By signing, you are proving you own this wallet and logging in. This does not initiate a transaction or cost any fees."
+	SIWEVersion   = "1"
+	SIWEChainID   = "1"
+	SIWEResource  = "https://privy.io"
+)
+
+// AuthenticateRequest 定义请求结构体
+type AuthenticateRequest struct {
+	Message          string `json:"message"`
+	Signature        string `json:"signature"`
+	ChainID          string `json:"chainId"`
+	WalletClientType string `json:"walletClientType"`
+	ConnectorType    string `json:"connectorType"`
+	Mode             string `json:"mode"`
+}
+
+// AuthenticateResponse 定义响应结构体
+type AuthenticateResponse struct {
+	User struct {
+		ID             string `json:"id"`
+		CreatedAt      int64  `json:"created_at"`
+		LinkedAccounts []struct {
+			Type              string `json:"type"`
+			Address           string `json:"address,omitempty"`
+			ChainType         string `json:"chain_type,omitempty"`
+			ChainID           string `json:"chain_id,omitempty"`
+			WalletClient      string `json:"wallet_client,omitempty"`
+			WalletClientType  string `json:"wallet_client_type,omitempty"`
+			ConnectorType     string `json:"connector_type,omitempty"`
+			VerifiedAt        int64  `json:"verified_at,omitempty"`
+			FirstVerifiedAt   int64  `json:"first_verified_at,omitempty"`
+			LatestVerifiedAt  int64  `json:"latest_verified_at,omitempty"`
+			Subject           string `json:"subject,omitempty"`
+			Name              string `json:"name,omitempty"`
+			Username          string `json:"username,omitempty"`
+			ProfilePictureURL string `json:"profile_picture_url,omitempty"`
+			Email             string `json:"email,omitempty"`
+		} `json:"linked_accounts"`
+		MFAMethods       []interface{} `json:"mfa_methods"`
+		HasAcceptedTerms bool          `json:"has_accepted_terms"`
+		IsGuest          bool          `json:"is_guest"`
+	} `json:"user"`
+	Token               string `json:"token"`
+	PrivyAccessToken    string `json:"privy_access_token"`
+	RefreshToken        string `json:"refresh_token"`
+	IdentityToken       string `json:"identity_token"`
+	SessionUpdateAction string `json:"session_update_action"`
+	IsNewUser           bool   `json:"is_new_user"`
+}
+
+// InitRequest 定义请求结构体
+type InitRequest struct {
+	Address string `json:"address"`
+}
+
+// InitResponse 定义响应结构体
+type InitResponse struct {
+	Nonce     string `json:"nonce"`
+	Address   string `json:"address"`
+	ExpiresAt string `json:"expires_at"`
+
+	// ServerTime 响应 Date 头中的服务器时间 (精确到秒)，没有时为零值
+	ServerTime time.Time `json:"-"`
+	// ReceivedAt 收到响应时的本地时间
+	ReceivedAt time.Time `json:"-"`
+}
+
+// NewInitRequest 构造 Privy SIWE 初始化请求
+func (c *Client) NewInitRequest(ctx context.Context, address string) (*http.Request, error) {
+	url := c.BaseURL + "/siwe/init"
+	requestBody, err := json.Marshal(InitRequest{Address: address})
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求体失败: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(httpclient.WithStage(ctx, StageInit), "POST", url, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+
+	setRequestHeaders(req)
+	return req, nil
+}
+
+// InitAuth 初始化Privy认证
+func (c *Client) InitAuth(ctx context.Context, address, proxyURL string) (*InitResponse, error) {
+	req, err := c.NewInitRequest(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	logging.Info("正在初始化 Privy 认证...")
+	var response InitResponse
+	header, err := c.send(req, proxyURL, &response)
+	if err != nil {
+		return nil, err
+	}
+	response.ReceivedAt = time.Now()
+	if date, err := http.ParseTime(header.Get("Date")); err == nil {
+		response.ServerTime = date
+	}
+	return &response, nil
+}
+
+// BuildAuthenticateRequest 生成 SIWE 消息与签名并构造 Privy 认证请求，purpose 为写入签名审计日志的用途
+func (c *Client) BuildAuthenticateRequest(privateKey, address, nonce, issuedAt, purpose string) (AuthenticateRequest, error) {
+	signature, msg, err := signer.SignEIP4361Message(
+		privateKey,
+		SIWEDomain,
+		address,
+		SIWEStatement,
+		SIWEURI,
+		SIWEVersion,
+		SIWEChainID,
+		nonce,
+		issuedAt,
+		[]string{SIWEResource},
+		c.Audit,
+		purpose,
+	)
+	if err != nil {
+		return AuthenticateRequest{}, fmt.Errorf("生成签名失败: %v", err)
+	}
+
+	return AuthenticateRequest{
+		Message:          msg,
+		Signature:        signature,
+		ChainID:          "eip155:" + SIWEChainID,
+		WalletClientType: "okx_wallet",
+		ConnectorType:    "injected",
+		Mode:             "login-or-sign-up",
+	}, nil
+}
+
+// NewAuthenticateRequest 构造 Privy SIWE 认证请求
+func (c *Client) NewAuthenticateRequest(ctx context.Context, request AuthenticateRequest) (*http.Request, error) {
+	url := c.BaseURL + "/siwe/authenticate"
+	requestBody, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求体失败: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(httpclient.WithStage(ctx, StageAuthenticate), "POST", url, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+
+	setRequestHeaders(req)
+	return req, nil
+}
+
+// Authenticate 向Privy认证服务发送请求
+func (c *Client) Authenticate(ctx context.Context, request AuthenticateRequest, proxyURL string) (*AuthenticateResponse, error) {
+	req, err := c.NewAuthenticateRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	logging.Info("正在向 Privy 发送认证请求...")
+	var response AuthenticateResponse
+	if _, err := c.send(req, proxyURL, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// RefreshRequest 定义请求结构体
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// NewRefreshRequest 构造 Privy 会话刷新请求
+func (c *Client) NewRefreshRequest(ctx context.Context, accessToken, refreshToken string) (*http.Request, error) {
+	url := c.BaseURL + "/sessions"
+	requestBody, err := json.Marshal(RefreshRequest{RefreshToken: refreshToken})
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求体失败: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(httpclient.WithStage(ctx, StageRefresh), "POST", url, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+
+	setRequestHeaders(req)
+	req.Header.Set("authorization", "Bearer "+accessToken)
+	return req, nil
+}
+
+// Refresh 使用刷新 Token 换取新的访问 Token，响应格式与认证相同
+func (c *Client) Refresh(ctx context.Context, accessToken, refreshToken, proxyURL string) (*AuthenticateResponse, error) {
+	req, err := c.NewRefreshRequest(ctx, accessToken, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	logging.Info("正在刷新 Privy 会话...")
+	var response AuthenticateResponse
+	if _, err := c.send(req, proxyURL, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// APIError Privy 返回的非 200 响应
+type APIError struct {
+	StatusCode int
+	Body       string
+	// Message 响应体中的 error 字段
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("非预期状态码: %d, 响应: %s", e.StatusCode, e.Body)
+}
+
+// newAPIError 根据状态码与响应体构造 APIError
+func newAPIError(statusCode int, body []byte) *APIError {
+	var payload struct {
+		Error string `json:"error"`
+	}
+	json.Unmarshal(body, &payload)
+	return &APIError{StatusCode: statusCode, Body: string(body), Message: payload.Error}
+}
+
+// IsNonceError 判断是否为 nonce 无效或已过期，重新获取 nonce 后可以重试
+func IsNonceError(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode >= http.StatusInternalServerError {
+		return false
+	}
+	message := strings.ToLower(apiErr.Message)
+	return strings.Contains(message, "nonce") && (strings.Contains(message, "invalid") || strings.Contains(message, "expired"))
+}
+
+// send 发送请求并把 JSON 响应解析到 v，支持 gzip 压缩，返回响应头
+func (c *Client) send(req *http.Request, proxyURL string, v interface{}) (http.Header, error) {
+	client, err := c.HTTP.ForRequest(req, proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("创建HTTP客户端失败: %v", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求发送失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, newAPIError(resp.StatusCode, body)
+	}
+
+	var reader io.ReadCloser
+	switch resp.Header.Get("Content-Encoding") {
+	case "gzip":
+		reader, err = gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("创建gzip读取器失败: %v", err)
+		}
+		defer reader.Close()
+	default:
+		reader = resp.Body
+	}
+
+	if err := json.NewDecoder(reader).Decode(v); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %v", err)
+	}
+	return resp.Header, nil
+}
+
+func setRequestHeaders(req *http.Request) {
+	headers := map[string]string{
+		"accept":             "application/json",
+		"accept-encoding":    "gzip, deflate, br, zstd",
+		"accept-language":    "zh-CN,zh;q=0.9,en;q=0.8",
+		"content-type":       "application/json",
+		"origin":             "https://campaign.coinshift.xyz",
+		"priority":           "u=1, i",
+		"privy-app-id":       "clphlvsh3034xjw0fvs59mrdc",
+		"privy-ca-id":        "e37a03d7-0a73-423e-b427-71b288d6c199",
+		"privy-client":       "react-auth:2.4.1",
+		"referer":            "https://campaign.coinshift.xyz/",
+		"sec-ch-ua":          `"Google Chrome";v="137", "Chromium";v="137", "Not/A)Brand";v="24"`,
+		"sec-ch-ua-mobile":   "?0",
+		"sec-ch-ua-platform": `"macOS"`,
+		"sec-fetch-dest":     "empty",
+		"sec-fetch-mode":     "cors",
+		"sec-fetch-site":     "cross-site",
+		"user-agent":         "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/137.0.0.0 Safari/537.36",
+	}
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+}
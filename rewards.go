@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"blockmesh/runner"
+)
+
+// printRewardReport 按账户、奖励类型、日期输出奖励汇总，labels 为地址 (小写) 到账户标签的映射
+func printRewardReport(w io.Writer, entries []runner.LedgerEntry, labels map[string]string) {
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "奖励账本为空")
+		return
+	}
+
+	byAccount := make(map[string]int)
+	byType := make(map[string]int)
+	byDay := make(map[string]int)
+	total := 0
+	for _, entry := range entries {
+		account := entry.Address
+		if label := labels[strings.ToLower(entry.Address)]; label != "" {
+			account += " (" + label + ")"
+		}
+		byAccount[account] += entry.Quantity
+		byType[entry.RewardType] += entry.Quantity
+		byDay[entry.Time.Local().Format(runner.DayLayout)] += entry.Quantity
+		total += entry.Quantity
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	printTotals(tw, "账户", byAccount)
+	printTotals(tw, "奖励类型", byType)
+	printTotals(tw, "日期", byDay)
+	fmt.Fprintf(tw, "合计\t%d\n", total)
+	tw.Flush()
+}
+
+func printTotals(w io.Writer, title string, totals map[string]int) {
+	keys := make([]string, 0, len(totals))
+	for key := range totals {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintf(w, "%s\t数量\n", title)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s\t%d\n", key, totals[key])
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,356 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"blockmesh/config"
+	"blockmesh/deform"
+	"blockmesh/internal/httpclient"
+	"blockmesh/internal/logging"
+	"blockmesh/signer"
+)
+
+// ActivityResult 记录单个活动的领取结果
+type ActivityResult struct {
+	ActivityID     string                       `json:"activity_id"`
+	Success        bool                         `json:"success"`
+	Outcome        deform.Outcome               `json:"outcome"`
+	NextEligibleAt *time.Time                   `json:"next_eligible_at,omitempty"`
+	Status         string                       `json:"status,omitempty"`
+	Points         int                          `json:"points,omitempty"`
+	MissionID      string                       `json:"mission_id,omitempty"`
+	MissionStatus  string                       `json:"mission_status,omitempty"`
+	MissionPoints  int                          `json:"mission_points,omitempty"`
+	Error          string                       `json:"error,omitempty"`
+	Result         *deform.VerifyActivityResult `json:"-"`
+
+	unauthenticated bool
+}
+
+// AccountResult 记录单个账户一次执行的结果，Index 为从 1 开始的账户序号
+type AccountResult struct {
+	Index      int              `json:"index"`
+	Address    string           `json:"address,omitempty"`
+	Label      string           `json:"label,omitempty"`
+	Tags       []string         `json:"tags,omitempty"`
+	Success    bool             `json:"success"`
+	Error      string           `json:"error,omitempty"`
+	Activities []ActivityResult `json:"activities,omitempty"`
+	StartedAt  time.Time        `json:"started_at"`
+	FinishedAt time.Time        `json:"finished_at"`
+}
+
+// fail 记录账户级失败并返回结果
+func (r *AccountResult) fail(format string, v ...interface{}) *AccountResult {
+	r.Error = fmt.Sprintf(format, v...)
+	r.FinishedAt = time.Now()
+	logging.Error("%s", r.Error)
+	return r
+}
+
+// maskToken 截取 Token 前 n 位用于日志输出
+func maskToken(token string, n int) string {
+	if len(token) <= n {
+		return token
+	}
+	return token[:n]
+}
+
+// Session 账户登录 Deform 后的会话信息
+type Session struct {
+	Address       string `json:"address"`
+	PrivyToken    string `json:"privy_token"`
+	RefreshToken  string `json:"refresh_token"`
+	IdentityToken string `json:"identity_token"`
+	DeformToken   string `json:"deform_token"`
+}
+
+// nonceExpiryMargin nonce 剩余有效期小于该值时重新获取
+const nonceExpiryMargin = 5 * time.Second
+
+// maxNonceAttempts 认证接口返回 nonce 无效或已过期时的最多尝试次数
+const maxNonceAttempts = 3
+
+// maxNonceRefetches 认证前 nonce 即将过期时最多重新获取的次数，不计入认证尝试次数
+const maxNonceRefetches = 3
+
+// LoginAccount 完成登录服务的签名认证并登录活动后端，clock 决定时钟偏差告警与 Issued At 是否按服务器时间校正
+func LoginAccount(ctx context.Context, providers Providers, clock config.ClockConfig, account config.AccountConfig, address string) (*Session, error) {
+	var tokens *AuthTokens
+	attempts, refetches := 0, 0
+	for {
+		// 获取待签名的 nonce
+		challenge, err := providers.Auth.InitNonce(ctx, address, account.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("初始化 Privy 认证失败: %v", err)
+		}
+		logging.Success("成功获取 Nonce: %s", challenge.Nonce)
+		checkClockSkew(challenge.ClockSkew, clock)
+
+		// 签名前检查 nonce 有效期。服务端有效期本身短于余量时重新获取也无济于事，达到上限后只要尚未过期就继续使用
+		if challenge.ExpiresWithin(serverNow(challenge), nonceExpiryMargin) {
+			if refetches < maxNonceRefetches {
+				refetches++
+				logging.Warning("Nonce 已过期或即将过期 (%s)，重新获取", challenge.ExpiresAt.Format(time.RFC3339))
+				continue
+			}
+			if challenge.ExpiresWithin(serverNow(challenge), 0) {
+				return nil, fmt.Errorf("认证失败: 重新获取 %d 次后 Nonce 仍已过期 (%s)", refetches, challenge.ExpiresAt.Format(time.RFC3339))
+			}
+		}
+
+		// 签名并认证
+		attempts++
+		tokens, err = providers.Auth.Authenticate(ctx, account.PrivateKey, address, challenge, alignedNow(challenge, clock), account.Proxy)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, ErrNonceExpired) || attempts >= maxNonceAttempts || ctx.Err() != nil {
+			return nil, fmt.Errorf("认证失败: %v", err)
+		}
+		logging.Warning("Nonce 无效或已过期，重新获取后第 %d 次重试: %v", attempts, err)
+	}
+
+	// 打印结果
+	logging.Success("%s 认证成功!", logging.IconSuccess)
+	logging.Info("用户ID: %s", tokens.UserID)
+	logging.Info("访问Token: %s...", maskToken(tokens.AccessToken, 30))
+	logging.Info("刷新Token: %s...", maskToken(tokens.RefreshToken, 10))
+	logging.Info("链接账户数: %d", tokens.LinkedAccounts)
+	logging.Info("是否新用户: %t", tokens.IsNewUser)
+
+	return loginBackend(ctx, providers, account, address, tokens)
+}
+
+// checkClockSkew 本地时钟与服务器时间偏差超过阈值时告警
+func checkClockSkew(skew time.Duration, clock config.ClockConfig) {
+	if skew.Abs() <= clock.SkewThreshold() {
+		return
+	}
+	direction := "慢"
+	if skew < 0 {
+		direction = "快"
+	}
+	hint := "，可在配置中开启 clock.align_to_server 按服务器时间签名"
+	if clock.AlignToServer {
+		hint = "，已按服务器时间校正 Issued At"
+	}
+	logging.Warning("本地时钟比 Privy 服务器%s %s%s", direction, skew.Abs().Round(time.Second), hint)
+}
+
+// serverNow 按测得的时钟偏差估算服务器当前时间。nonce 的过期时间由服务器签发，始终按服务器时间判断
+func serverNow(challenge *Challenge) time.Time {
+	return time.Now().Add(challenge.ClockSkew)
+}
+
+// alignedNow 返回签名使用的 Issued At，开启 align_to_server 时按服务器时间校正
+func alignedNow(challenge *Challenge, clock config.ClockConfig) time.Time {
+	if clock.AlignToServer {
+		return serverNow(challenge)
+	}
+	return time.Now()
+}
+
+// RefreshSession 使用会话中的刷新 Token 换取新 Token 并重新登录活动后端，无需重新签名
+func RefreshSession(ctx context.Context, providers Providers, account config.AccountConfig, session *Session) (*Session, error) {
+	tokens, err := providers.Auth.Refresh(ctx, AuthTokens{
+		AccessToken:   session.PrivyToken,
+		RefreshToken:  session.RefreshToken,
+		IdentityToken: session.IdentityToken,
+	}, account.Proxy)
+	if err != nil {
+		return nil, fmt.Errorf("刷新会话失败: %v", err)
+	}
+	logging.Success("%s 会话刷新成功", logging.IconSuccess)
+	return loginBackend(ctx, providers, account, session.Address, tokens)
+}
+
+// RenewSession 优先用 previous 中的刷新 Token 续期，失败或没有刷新 Token 时重新签名登录
+func RenewSession(ctx context.Context, providers Providers, clock config.ClockConfig, account config.AccountConfig, address string, previous *Session) (*Session, error) {
+	if previous != nil && previous.RefreshToken != "" {
+		session, err := RefreshSession(ctx, providers, account, previous)
+		if err == nil {
+			return session, nil
+		}
+		logging.Warning("%v，重新签名登录", err)
+	}
+	return LoginAccount(ctx, providers, clock, account, address)
+}
+
+// loginBackend 使用登录服务的 Token 登录活动后端
+func loginBackend(ctx context.Context, providers Providers, account config.AccountConfig, address string, tokens *AuthTokens) (*Session, error) {
+	token, err := providers.Backend.Login(ctx, tokens.AccessToken, account.Proxy)
+	if err != nil {
+		return nil, fmt.Errorf("登录失败: %v", err)
+	}
+	logging.Success("登录成功! Token: %s...\n", maskToken(token, 30))
+
+	return &Session{
+		Address:       address,
+		PrivyToken:    tokens.AccessToken,
+		RefreshToken:  tokens.RefreshToken,
+		IdentityToken: tokens.IdentityToken,
+		DeformToken:   token,
+	}, nil
+}
+
+// 活动之间的间隔与失败重试的退避基数，测试中设为 0
+var (
+	activityDelay = 1 * time.Second
+	retryBackoff  = 2 * time.Second
+)
+
+// processOptions 账户执行流程的选项
+type processOptions struct {
+	Activities []config.ActivityConfig
+	Retries    int
+	Sessions   *SessionStore
+	State      *StateStore
+	Providers  Providers
+	Clock      config.ClockConfig
+}
+
+// processAccount 执行单个账户的完整流程：认证、登录、领取活动
+func processAccount(ctx context.Context, index int, account config.AccountConfig, opts processOptions) *AccountResult {
+	result := &AccountResult{Index: index + 1, Label: account.Label, Tags: account.Tags, StartedAt: time.Now()}
+	logging.Info("处理第 %d 个账户%s (代理: %s)", index+1, account.DisplaySuffix(), account.Proxy)
+
+	// 获取地址
+	address, err := signer.GetAddressFromPrivateKey(account.PrivateKey)
+	if err != nil {
+		return result.fail("获取地址失败: %v", err)
+	}
+	result.Address = address
+	logging.Success("%s 地址: %s", logging.IconAddress, address)
+	ctx = httpclient.WithAccount(ctx, address)
+
+	// 优先复用缓存的会话
+	session := opts.Sessions.Get(address)
+	cached := session != nil
+	if cached {
+		logging.Success("复用缓存的登录会话")
+	} else {
+		session, err = RenewSession(ctx, opts.Providers, opts.Clock, account, address, opts.Sessions.Last(address))
+		if err != nil {
+			return result.fail("%v", err)
+		}
+		opts.Sessions.Put(session)
+	}
+
+	// 循环处理每个活动ID
+	result.Success = true
+	for _, activity := range opts.Activities {
+		claimed := opts.State.Completions(address, activity.ID) > 0
+		activityResult := claimActivity(ctx, opts.Providers.Backend, session, account, activity.ID, claimed, opts.Retries)
+		if cached && activityResult.unauthenticated {
+			logging.Warning("缓存的登录会话已失效，重新登录")
+			opts.Sessions.Delete(address)
+			cached = false
+			if session, err = RenewSession(ctx, opts.Providers, opts.Clock, account, address, session); err != nil {
+				return result.fail("%v", err)
+			}
+			opts.Sessions.Put(session)
+			activityResult = claimActivity(ctx, opts.Providers.Backend, session, account, activity.ID, claimed, opts.Retries)
+		}
+		if !activityResult.Success {
+			result.Success = false
+		}
+		result.Activities = append(result.Activities, activityResult)
+
+		// 可选：添加延迟避免请求过于频繁
+		if err := sleepContext(ctx, activityDelay); err != nil {
+			result.FinishedAt = time.Now()
+			result.Success = false
+			result.Error = "执行已取消"
+			return result
+		}
+	}
+
+	result.FinishedAt = time.Now()
+	return result
+}
+
+// claimActivity 领取单个活动，只有真正失败时才会重试。
+// 冷却中的活动跳过，不算失败；claimed (本地有领取记录) 只用于在日志中说明冷却的原因
+func claimActivity(ctx context.Context, backend CampaignBackend, session *Session, account config.AccountConfig, activityID string, claimed bool, retries int) ActivityResult {
+	activityResult := ActivityResult{ActivityID: activityID}
+	for attempt := 0; ; attempt++ {
+		verifyResult, err := backend.VerifyActivity(ctx, activityID, session.DeformToken, session.IdentityToken, account.Proxy)
+		outcome, nextEligible := deform.ClassifyOutcome(err)
+		activityResult.Outcome = outcome
+		activityResult.NextEligibleAt = nil
+		if !nextEligible.IsZero() {
+			activityResult.NextEligibleAt = &nextEligible
+		}
+		activityResult.Success = outcome.Succeeded()
+		activityResult.Error = ""
+		if err != nil {
+			activityResult.Error = err.Error()
+		}
+		if activityResult.unauthenticated = deform.IsAuthError(err); activityResult.unauthenticated {
+			logging.Error("活动 %s 领取失败: %v", activityID, err)
+			return activityResult
+		}
+
+		switch outcome {
+		case deform.OutcomeCompleted:
+			activityResult.Status = verifyResult.Record.Status
+			activityResult.Result = verifyResult
+			for _, reward := range verifyResult.Record.RewardRecords {
+				activityResult.Points += deform.RewardQuantity(reward)
+			}
+			logging.Success("活动 %s 领取成功! 获得 %d 奖励\n", activityID, activityResult.Points)
+
+			if mission := verifyResult.MissionRecord; mission != nil {
+				activityResult.MissionID = mission.MissionID
+				activityResult.MissionStatus = mission.Status
+				for _, reward := range mission.RewardRecords {
+					activityResult.MissionPoints += deform.RewardQuantity(reward)
+				}
+				logging.Success("活动 %s 完成任务 %s (状态: %s)，任务奖励 %d\n", activityID, mission.MissionID, mission.Status, activityResult.MissionPoints)
+			}
+			return activityResult
+		case deform.OutcomeAlreadyCompleted:
+			logging.Warning("活动 %s 今日已领取，跳过", activityID)
+			return activityResult
+		case deform.OutcomeOnCooldown:
+			next := "未知"
+			if activityResult.NextEligibleAt != nil {
+				next = activityResult.NextEligibleAt.Local().Format(time.RFC3339)
+			}
+			if claimed {
+				logging.Warning("活动 %s 冷却中，跳过 (下次可领取时间: %s)", activityID, next)
+			} else {
+				logging.Warning("活动 %s 冷却中，跳过 (下次可领取时间: %s)，本地没有领取记录，可能已在其它设备上领取", activityID, next)
+			}
+			return activityResult
+		case deform.OutcomeIneligible:
+			logging.Error("活动 %s 不符合领取条件: %v", activityID, err)
+			return activityResult
+		}
+
+		if attempt >= retries || ctx.Err() != nil {
+			logging.Error("活动 %s 领取失败: %v", activityID, err)
+			return activityResult
+		}
+		logging.Warning("活动 %s 领取失败，第 %d 次重试: %v", activityID, attempt+1, err)
+		if sleepContext(ctx, time.Duration(attempt+1)*retryBackoff) != nil {
+			return activityResult
+		}
+	}
+}
+
+// sleepContext 等待指定时长，context 取消时提前返回
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,157 @@
+package runner
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"blockmesh/deform"
+	"blockmesh/internal/logging"
+)
+
+// defaultLedgerFile 默认奖励账本路径
+const defaultLedgerFile = "rewards.jsonl"
+
+// LedgerEntry 奖励账本中的一条记录
+type LedgerEntry struct {
+	Time           time.Time `json:"time"`
+	Address        string    `json:"address"`
+	ActivityID     string    `json:"activity_id"`
+	RecordID       string    `json:"record_id"`
+	RewardRecordID string    `json:"reward_record_id"`
+	MissionID      string    `json:"mission_id,omitempty"`
+	RewardID       string    `json:"reward_id,omitempty"`
+	RewardType     string    `json:"reward_type"`
+	Quantity       int       `json:"quantity"`
+	RewardQuantity int       `json:"reward_quantity"`
+	Status         string    `json:"status,omitempty"`
+}
+
+// key 返回用于去重的键。奖励记录没有 ID 时，按地址、活动、任务、日期与数量生成，同一天重复领取同一奖励只记录一次
+func (e LedgerEntry) key() string {
+	if e.RewardRecordID != "" {
+		return e.RewardRecordID
+	}
+	return fmt.Sprintf("%s|%s|%s|%s|%s|%d", strings.ToLower(e.Address), e.ActivityID, e.MissionID,
+		e.Time.UTC().Format("2006-01-02"), e.RewardType, e.Quantity)
+}
+
+// Ledger 以 JSON Lines 追加写入的本地奖励账本
+type Ledger struct {
+	mu   sync.Mutex
+	path string
+	seen map[string]bool
+}
+
+// OpenLedger 打开奖励账本，并读取已有记录用于去重
+func OpenLedger(path string) (*Ledger, error) {
+	if path == "" {
+		path = defaultLedgerFile
+	}
+	ledger := &Ledger{path: path, seen: make(map[string]bool)}
+	entries, err := ledger.Entries()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		ledger.seen[entry.key()] = true
+	}
+	return ledger, nil
+}
+
+// RecordActivity 将一次活动领取结果中的活动奖励与任务奖励写入账本，已记录的奖励会被跳过
+func (l *Ledger) RecordActivity(address string, at time.Time, result *deform.VerifyActivityResult) error {
+	if result == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	record := result.Record
+	var entries []LedgerEntry
+	pending := make(map[string]bool)
+	newEntry := func(recordID, missionID string, reward deform.RewardRecord) {
+		entry := LedgerEntry{
+			Time:           at,
+			Address:        address,
+			ActivityID:     record.ActivityID,
+			RecordID:       recordID,
+			RewardRecordID: reward.ID,
+			MissionID:      missionID,
+			RewardID:       reward.RewardID,
+			RewardType:     deform.RewardType(reward),
+			Quantity:       deform.RewardQuantity(reward),
+			RewardQuantity: reward.Reward.Quantity,
+			Status:         reward.Status,
+		}
+		key := entry.key()
+		if l.seen[key] || pending[key] {
+			return
+		}
+		pending[key] = true
+		if reward.ID == "" {
+			logging.Warning("活动 %s 的奖励记录没有 ID，按日期与数量去重后记录", record.ActivityID)
+		}
+		entries = append(entries, entry)
+	}
+	for _, reward := range record.RewardRecords {
+		newEntry(record.ID, "", reward)
+	}
+	if mission := result.MissionRecord; mission != nil {
+		for _, reward := range mission.RewardRecords {
+			newEntry(mission.ID, mission.MissionID, reward)
+		}
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("打开奖励账本失败: %v", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return fmt.Errorf("写入奖励账本失败: %v", err)
+		}
+		l.seen[entry.key()] = true
+	}
+	return nil
+}
+
+// Entries 读取账本中的全部记录
+func (l *Ledger) Entries() ([]LedgerEntry, error) {
+	file, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开奖励账本失败: %v", err)
+	}
+	defer file.Close()
+
+	var entries []LedgerEntry
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var entry LedgerEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("解析奖励账本第 %d 行失败: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取奖励账本失败: %v", err)
+	}
+	return entries, nil
+}
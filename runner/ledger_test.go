@@ -0,0 +1,63 @@
+package runner
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"blockmesh/deform"
+)
+
+func ledgerResult(rewardIDs ...string) *deform.VerifyActivityResult {
+	result := &deform.VerifyActivityResult{}
+	result.Record.ID = "record-1"
+	result.Record.ActivityID = "activity-1"
+	for _, id := range rewardIDs {
+		reward := deform.RewardRecord{ID: id, AppliedRewardType: "POINTS", AppliedRewardQuantity: 10}
+		result.Record.RewardRecords = append(result.Record.RewardRecords, reward)
+	}
+	return result
+}
+
+func TestLedgerRecordsRewardsWithoutID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rewards.jsonl")
+	ledger, err := OpenLedger(path)
+	if err != nil {
+		t.Fatalf("OpenLedger: %v", err)
+	}
+	at := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	if err := ledger.RecordActivity("0xabc", at, ledgerResult("reward-1", "")); err != nil {
+		t.Fatalf("RecordActivity: %v", err)
+	}
+	// 同一天重复领取，两条奖励都应被去重
+	if err := ledger.RecordActivity("0xABC", at.Add(time.Hour), ledgerResult("reward-1", "")); err != nil {
+		t.Fatalf("RecordActivity: %v", err)
+	}
+
+	reopened, err := OpenLedger(path)
+	if err != nil {
+		t.Fatalf("OpenLedger: %v", err)
+	}
+	entries, err := reopened.Entries()
+	if err != nil {
+		t.Fatalf("Entries: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("账本应有 2 条记录，实际 %d 条", len(entries))
+	}
+	if entries[1].RewardRecordID != "" || entries[1].Quantity != 10 {
+		t.Fatalf("没有 ID 的奖励记录不正确: %+v", entries[1])
+	}
+
+	// 重新打开后去重仍然生效，次日的奖励正常记录
+	if err := reopened.RecordActivity("0xabc", at.Add(2*time.Hour), ledgerResult("")); err != nil {
+		t.Fatalf("RecordActivity: %v", err)
+	}
+	if err := reopened.RecordActivity("0xabc", at.Add(24*time.Hour), ledgerResult("")); err != nil {
+		t.Fatalf("RecordActivity: %v", err)
+	}
+	entries, _ = reopened.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("账本应有 3 条记录，实际 %d 条", len(entries))
+	}
+}
@@ -0,0 +1,240 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"blockmesh/config"
+	"blockmesh/internal/logging"
+)
+
+const (
+	defaultTelegramBaseURL = "https://api.telegram.org"
+	defaultStreakDays      = 3
+	discordContentLimit    = 2000
+)
+
+// defaultNotifyTemplate 默认通知模板
+const defaultNotifyTemplate = `Coinshift 签到完成: 成功 {{.Succeeded}}/{{.Total}}，失败 {{.Failed}}
+{{- range .Failures}}
+❌ #{{.Index}}{{with .Label}} {{.}}{{end}} {{.Address}} {{.Error}}{{with index $.Streaks .Address}} (连续失败 {{.}} 天){{end}}
+{{- end}}`
+
+// RunSummary 一次执行的汇总，作为通知模板的数据
+type RunSummary struct {
+	StartedAt  time.Time        `json:"started_at"`
+	FinishedAt time.Time        `json:"finished_at"`
+	Total      int              `json:"total"`
+	Succeeded  int              `json:"succeeded"`
+	Failed     int              `json:"failed"`
+	Results    []*AccountResult `json:"results"`
+	Streaks    map[string]int   `json:"streaks,omitempty"`
+}
+
+// FailureEntry 失败账户的简要信息
+type FailureEntry struct {
+	Index   int
+	Label   string
+	Tags    []string
+	Address string
+	Error   string
+}
+
+// Failures 返回失败账户列表，供模板使用
+func (s *RunSummary) Failures() []FailureEntry {
+	var failures []FailureEntry
+	for _, result := range s.Results {
+		if result.Success {
+			continue
+		}
+		message := result.Error
+		if message == "" {
+			var failed []string
+			for _, activity := range result.Activities {
+				if !activity.Success {
+					failed = append(failed, fmt.Sprintf("%s(%s)", activity.ActivityID, activity.Outcome))
+				}
+			}
+			message = "活动领取失败: " + strings.Join(failed, ", ")
+		}
+		failures = append(failures, FailureEntry{Index: result.Index, Label: result.Label, Tags: result.Tags, Address: result.Address, Error: message})
+	}
+	return failures
+}
+
+// MaxStreak 返回所有账户中最长的连续失败天数
+func (s *RunSummary) MaxStreak() int {
+	max := 0
+	for _, days := range s.Streaks {
+		if days > max {
+			max = days
+		}
+	}
+	return max
+}
+
+// newRunSummary 根据执行结果与状态生成汇总
+func newRunSummary(startedAt time.Time, results []*AccountResult, state *StateStore) *RunSummary {
+	summary := &RunSummary{
+		StartedAt:  startedAt,
+		FinishedAt: time.Now(),
+		Total:      len(results),
+		Results:    results,
+		Streaks:    make(map[string]int),
+	}
+	for _, result := range results {
+		if result.Success {
+			summary.Succeeded++
+		} else {
+			summary.Failed++
+		}
+		if accountState := state.Get(result.Address); accountState != nil && accountState.ConsecutiveFailureDays > 0 {
+			summary.Streaks[result.Address] = accountState.ConsecutiveFailureDays
+		}
+	}
+	return summary
+}
+
+// Notifier 通知渠道
+type Notifier struct {
+	config   config.NotifierConfig
+	template *template.Template
+	client   *http.Client
+}
+
+// NewNotifier 根据配置创建通知渠道
+func NewNotifier(cfg config.NotifierConfig) (*Notifier, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Trigger == "" {
+		cfg.Trigger = config.TriggerAlways
+	}
+	if cfg.StreakDays <= 0 {
+		cfg.StreakDays = defaultStreakDays
+	}
+
+	text := cfg.Template
+	if text == "" {
+		text = defaultNotifyTemplate
+	}
+	tmpl, err := template.New(cfg.Type).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("解析通知模板失败: %v", err)
+	}
+
+	return &Notifier{
+		config:   cfg,
+		template: tmpl,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// ShouldNotify 判断本次执行是否满足触发条件
+func (n *Notifier) ShouldNotify(summary *RunSummary) bool {
+	switch n.config.Trigger {
+	case config.TriggerFailure:
+		return summary.Failed > 0
+	case config.TriggerStreak:
+		return summary.MaxStreak() >= n.config.StreakDays
+	default:
+		return true
+	}
+}
+
+// Notify 渲染模板并发送通知
+func (n *Notifier) Notify(ctx context.Context, summary *RunSummary) error {
+	var text bytes.Buffer
+	if err := n.template.Execute(&text, summary); err != nil {
+		return fmt.Errorf("渲染通知模板失败: %v", err)
+	}
+
+	endpoint, payload := n.payload(text.String(), summary)
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("序列化通知失败: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %v", stripURL(err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求发送失败: %v", stripURL(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("非预期状态码: %d, 响应: %s", resp.StatusCode, respBody)
+	}
+	return nil
+}
+
+// stripURL 去掉错误中的请求地址。Telegram 的 Bot Token 与 Discord、Webhook 的密钥都在地址中，不能写入日志
+func stripURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("%s: %v", urlErr.Op, urlErr.Err)
+	}
+	return err
+}
+
+// payload 按渠道格式构造请求地址与请求体
+func (n *Notifier) payload(text string, summary *RunSummary) (string, interface{}) {
+	switch n.config.Type {
+	case config.NotifierTelegram:
+		baseURL := n.config.BaseURL
+		if baseURL == "" {
+			baseURL = defaultTelegramBaseURL
+		}
+		endpoint := strings.TrimRight(baseURL, "/") + "/bot" + n.config.BotToken + "/sendMessage"
+		return endpoint, map[string]interface{}{
+			"chat_id": n.config.ChatID,
+			"text":    text,
+		}
+	case config.NotifierDiscord:
+		if len([]rune(text)) > discordContentLimit {
+			text = string([]rune(text)[:discordContentLimit-1]) + "…"
+		}
+		return n.config.URL, map[string]interface{}{
+			"content": text,
+		}
+	default:
+		return n.config.URL, map[string]interface{}{
+			"text":    text,
+			"summary": summary,
+		}
+	}
+}
+
+// sendNotifications 向所有满足触发条件的渠道发送通知
+func sendNotifications(ctx context.Context, configs []config.NotifierConfig, summary *RunSummary) {
+	for _, cfg := range configs {
+		notifier, err := NewNotifier(cfg)
+		if err != nil {
+			logging.Error("通知配置错误: %v", err)
+			continue
+		}
+		if !notifier.ShouldNotify(summary) {
+			continue
+		}
+		if err := notifier.Notify(ctx, summary); err != nil {
+			logging.Error("发送 %s 通知失败: %v", cfg.Type, err)
+			continue
+		}
+		logging.Success("已发送 %s 通知", cfg.Type)
+	}
+}
@@ -0,0 +1,28 @@
+package runner
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"blockmesh/config"
+)
+
+func TestNotifyErrorDoesNotLeakBotToken(t *testing.T) {
+	notifier, err := NewNotifier(config.NotifierConfig{
+		Type:     config.NotifierTelegram,
+		BaseURL:  "http://127.0.0.1:1",
+		BotToken: "123:SECRETTOKEN",
+		ChatID:   "42",
+	})
+	if err != nil {
+		t.Fatalf("NewNotifier: %v", err)
+	}
+	err = notifier.Notify(context.Background(), &RunSummary{})
+	if err == nil {
+		t.Fatal("Notify 应当失败")
+	}
+	if strings.Contains(err.Error(), "SECRETTOKEN") {
+		t.Fatalf("错误信息包含 Bot Token: %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"blockmesh/config"
+	"blockmesh/deform"
+	"blockmesh/internal/httpclient"
+	"blockmesh/privy"
+	"blockmesh/signer"
+	"blockmesh/siwe"
+)
+
+// ErrNonceExpired nonce 无效或已过期，重新获取 nonce 后可以重试。
+// AuthProvider.Authenticate 遇到这类错误时应返回包装了它的错误
+var ErrNonceExpired = errors.New("nonce 无效或已过期")
+
+// Challenge 登录服务下发的待签名 nonce
+type Challenge struct {
+	Nonce string
+	// ExpiresAt nonce 的过期时间，服务端未返回时为零值
+	ExpiresAt time.Time
+	// ClockSkew 服务器时间减去本地时间，无法测量时为 0
+	ClockSkew time.Duration
+}
+
+// ExpiresWithin 判断 nonce 是否会在 d 内过期，服务端未返回过期时间时视为不会过期
+func (c *Challenge) ExpiresWithin(now time.Time, d time.Duration) bool {
+	return !c.ExpiresAt.IsZero() && !now.Add(d).Before(c.ExpiresAt)
+}
+
+// AuthTokens 登录服务签发的 Token
+type AuthTokens struct {
+	UserID         string
+	AccessToken    string
+	RefreshToken   string
+	IdentityToken  string
+	LinkedAccounts int
+	IsNewUser      bool
+}
+
+// AuthProvider 钱包签名登录服务，默认实现为 Privy
+type AuthProvider interface {
+	// InitNonce 获取地址对应的待签名 nonce
+	InitNonce(ctx context.Context, address, proxyURL string) (*Challenge, error)
+	// Authenticate 使用私钥签名 nonce 并换取 Token，issuedAt 为签名消息中的 Issued At
+	Authenticate(ctx context.Context, privateKey, address string, challenge *Challenge, issuedAt time.Time, proxyURL string) (*AuthTokens, error)
+	// Refresh 使用刷新 Token 换取新的 Token，无需重新签名
+	Refresh(ctx context.Context, tokens AuthTokens, proxyURL string) (*AuthTokens, error)
+}
+
+// CampaignBackend 活动后端，默认实现为 Deform (*deform.Client)。
+// VerifyActivity 返回的业务错误应为 *deform.ActivityError，以便按领取结果分类
+type CampaignBackend interface {
+	// Login 使用登录服务的访问 Token 登录，返回后端 Token
+	Login(ctx context.Context, authToken, proxyURL string) (string, error)
+	// VerifyActivity 领取指定活动
+	VerifyActivity(ctx context.Context, activityID, bearerToken, identityToken, proxyURL string) (*deform.VerifyActivityResult, error)
+	// QueryCampaignActivities 查询活动下的全部任务
+	QueryCampaignActivities(ctx context.Context, campaignID, proxyURL string) (*deform.CampaignInfo, error)
+	// QueryUserProfile 查询当前登录用户的资料、积分与排名
+	QueryUserProfile(ctx context.Context, campaignID, bearerToken, identityToken, proxyURL string) (*deform.UserProfile, error)
+}
+
+// Providers 账户流程使用的登录服务与活动后端
+type Providers struct {
+	Auth    AuthProvider
+	Backend CampaignBackend
+}
+
+// ClientOptions 默认的 Privy 与 Deform 客户端共用的设置
+type ClientOptions struct {
+	// HTTP 发送请求使用的连接池、超时与录制/回放设置，为 nil 时使用默认设置
+	HTTP *httpclient.Client
+	// Audit 签名审计日志，为 nil 时不记录
+	Audit *signer.AuditLog
+}
+
+// NewClientOptions 按配置中的请求超时与签名审计日志创建客户端设置，使用新的连接池，直接访问网络
+func NewClientOptions(cfg *config.Config) ClientOptions {
+	return ClientOptions{
+		HTTP:  httpclient.New(nil, httpTimeouts(cfg), nil),
+		Audit: signer.NewAuditLog(cfg.AuditFile),
+	}
+}
+
+// httpTimeouts 返回配置中的请求超时
+func httpTimeouts(cfg *config.Config) httpclient.Timeouts {
+	d, stages := cfg.HTTP.Timeouts()
+	return httpclient.Timeouts{Default: d, Stages: stages}
+}
+
+// NewClients 按配置中的接口地址创建 Privy 与 Deform 客户端，未配置时使用默认地址
+func NewClients(endpoints config.EndpointsConfig, opts ClientOptions) (*privy.Client, *deform.Client) {
+	privyClient := privy.NewClient(endpoints.PrivyBaseURL)
+	deformClient := deform.NewClient(endpoints.DeformAPIURL)
+	if opts.HTTP != nil {
+		privyClient.HTTP = opts.HTTP
+		deformClient.HTTP = opts.HTTP
+	}
+	privyClient.Audit = opts.Audit
+	return privyClient, deformClient
+}
+
+// DefaultProviders 按配置中的接口地址创建 Privy 与 Deform 实现，未配置时使用默认地址
+func DefaultProviders(endpoints config.EndpointsConfig, opts ClientOptions) Providers {
+	privyClient, deformClient := NewClients(endpoints, opts)
+	return Providers{
+		Auth:    NewPrivyAuth(privyClient),
+		Backend: deformClient,
+	}
+}
+
+// privyAuth 基于 Privy SIWE 的 AuthProvider
+type privyAuth struct {
+	client *privy.Client
+}
+
+// NewPrivyAuth 使用 Privy 客户端创建 AuthProvider
+func NewPrivyAuth(client *privy.Client) AuthProvider {
+	return &privyAuth{client: client}
+}
+
+func (p *privyAuth) InitNonce(ctx context.Context, address, proxyURL string) (*Challenge, error) {
+	response, err := p.client.InitAuth(ctx, address, proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	challenge := &Challenge{Nonce: response.Nonce}
+	if expiresAt, err := time.Parse(time.RFC3339Nano, response.ExpiresAt); err == nil {
+		challenge.ExpiresAt = expiresAt
+	}
+	if !response.ServerTime.IsZero() {
+		challenge.ClockSkew = response.ServerTime.Sub(response.ReceivedAt)
+	}
+	return challenge, nil
+}
+
+func (p *privyAuth) Authenticate(ctx context.Context, privateKey, address string, challenge *Challenge, issuedAt time.Time, proxyURL string) (*AuthTokens, error) {
+	request, err := p.client.BuildAuthenticateRequest(privateKey, address, challenge.Nonce, siwe.FormatTime(issuedAt), signer.PurposeLogin)
+	if err != nil {
+		return nil, err
+	}
+	response, err := p.client.Authenticate(ctx, request, proxyURL)
+	if privy.IsNonceError(err) {
+		return nil, fmt.Errorf("%w: %v", ErrNonceExpired, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return privyTokens(response), nil
+}
+
+func (p *privyAuth) Refresh(ctx context.Context, tokens AuthTokens, proxyURL string) (*AuthTokens, error) {
+	if tokens.RefreshToken == "" {
+		return nil, fmt.Errorf("缺少刷新 Token")
+	}
+	response, err := p.client.Refresh(ctx, tokens.AccessToken, tokens.RefreshToken, proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	return privyTokens(response), nil
+}
+
+// privyTokens 从 Privy 认证响应中提取 Token
+func privyTokens(response *privy.AuthenticateResponse) *AuthTokens {
+	return &AuthTokens{
+		UserID:         response.User.ID,
+		AccessToken:    response.Token,
+		RefreshToken:   response.RefreshToken,
+		IdentityToken:  response.IdentityToken,
+		LinkedAccounts: len(response.User.LinkedAccounts),
+		IsNewUser:      response.IsNewUser,
+	}
+}
@@ -0,0 +1,179 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"syscall"
+	"time"
+
+	"blockmesh/config"
+	"blockmesh/internal/logging"
+	"blockmesh/signer"
+)
+
+// DefaultWatchInterval 常驻模式下检查配置文件是否修改的间隔
+const DefaultWatchInterval = 5 * time.Second
+
+// Watch 在配置文件修改或收到 SIGHUP 时重新加载配置，执行期间的修改会在执行结束后生效，直到 ctx 被取消
+func (r *Runner) Watch(ctx context.Context, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	lastMod := configModTime(r.configPath)
+	pending := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			logging.Info("收到 SIGHUP，重新加载配置")
+			pending = true
+		case <-tick:
+			if modTime := configModTime(r.configPath); !modTime.Equal(lastMod) {
+				lastMod = modTime
+				logging.Info("配置文件已修改，重新加载配置")
+				pending = true
+			}
+		}
+		if !pending {
+			continue
+		}
+
+		lastMod = configModTime(r.configPath)
+		_, err := r.Reload()
+		switch {
+		case errors.Is(err, ErrRunInProgress):
+			// 等待本次执行结束后再重新加载
+			if tick == nil {
+				logging.Warning("正在执行任务，请在执行结束后重新发送 SIGHUP")
+				pending = false
+			}
+		case err != nil:
+			logging.Error("重新加载配置失败，继续使用原配置: %v", err)
+			pending = false
+		default:
+			pending = false
+		}
+	}
+}
+
+// configModTime 返回配置文件的修改时间，读取失败时返回零值
+func configModTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// configDiff 对比新旧配置，返回账户增删、账户设置变化与活动启停等差异
+func configDiff(old, new *config.Config) []string {
+	var changes []string
+	oldAccounts := accountsByAddress(old.Accounts)
+	newAccounts := accountsByAddress(new.Accounts)
+	for i, account := range new.Accounts {
+		address, _ := signer.GetAddressFromPrivateKey(account.PrivateKey)
+		previous, ok := oldAccounts[strings.ToLower(address)]
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("新增账户 #%d%s %s", i+1, account.DisplaySuffix(), address))
+		case previous.Proxy != account.Proxy:
+			changes = append(changes, fmt.Sprintf("账户 #%d%s 代理已修改", i+1, account.DisplaySuffix()))
+		case previous.Label != account.Label || !reflect.DeepEqual(previous.Tags, account.Tags):
+			changes = append(changes, fmt.Sprintf("账户 #%d 标签修改为 %s", i+1, valueOrNone(account.Display())))
+		}
+	}
+	for i, account := range old.Accounts {
+		address, _ := signer.GetAddressFromPrivateKey(account.PrivateKey)
+		if _, ok := newAccounts[strings.ToLower(address)]; !ok {
+			changes = append(changes, fmt.Sprintf("移除账户 (原 #%d)%s %s", i+1, account.DisplaySuffix(), address))
+		}
+	}
+
+	if old.Campaign.ID != new.Campaign.ID {
+		changes = append(changes, fmt.Sprintf("活动ID修改为 %s", valueOrNone(new.Campaign.ID)))
+	}
+	oldActivities := enabledActivitySet(old.Campaign)
+	newActivities := enabledActivitySet(new.Campaign)
+	for _, activity := range new.Campaign.EnabledActivities() {
+		if !oldActivities[activity.ID] {
+			changes = append(changes, fmt.Sprintf("启用活动 %s", new.Campaign.ActivityName(activity.ID)))
+		}
+	}
+	for _, activity := range old.Campaign.EnabledActivities() {
+		if !newActivities[activity.ID] {
+			changes = append(changes, fmt.Sprintf("停用活动 %s", old.Campaign.ActivityName(activity.ID)))
+		}
+	}
+
+	if old.ActivityRetries() != new.ActivityRetries() {
+		changes = append(changes, fmt.Sprintf("重试次数修改为 %d", new.ActivityRetries()))
+	}
+	if !reflect.DeepEqual(old.Endpoints, new.Endpoints) {
+		changes = append(changes, "上游服务地址已修改")
+	}
+	if !reflect.DeepEqual(old.HTTP, new.HTTP) {
+		changes = append(changes, "请求超时已修改")
+	}
+	if old.Clock != new.Clock {
+		changes = append(changes, "时钟偏差设置已修改")
+	}
+	if !reflect.DeepEqual(old.Notifiers, new.Notifiers) {
+		changes = append(changes, fmt.Sprintf("通知配置已修改 (%d 个渠道)", len(new.Notifiers)))
+	}
+	if old.API != new.API {
+		changes = append(changes, "管理 API 配置已修改，需要重启后生效")
+	}
+	if old.StateFile != new.StateFile || old.LedgerFile != new.LedgerFile || old.SessionFile != new.SessionFile {
+		changes = append(changes, "state_file、ledger_file 或 session_file 已修改，需要重启后生效")
+	}
+	if (old.EncryptionPassphrase() == "") != (new.EncryptionPassphrase() == "") {
+		changes = append(changes, "配置文件加密状态已修改，会话缓存需要重启后按新方式保存")
+	}
+	if old.LockFile != new.LockFile {
+		changes = append(changes, "lock_file 已修改，需要重启后生效")
+	}
+	if old.AuditFile != new.AuditFile {
+		changes = append(changes, fmt.Sprintf("签名审计日志修改为 %s", valueOrNone(new.AuditFile)))
+	}
+	return changes
+}
+
+func accountsByAddress(accounts []config.AccountConfig) map[string]config.AccountConfig {
+	byAddress := make(map[string]config.AccountConfig, len(accounts))
+	for _, account := range accounts {
+		if address, err := signer.GetAddressFromPrivateKey(account.PrivateKey); err == nil {
+			byAddress[strings.ToLower(address)] = account
+		}
+	}
+	return byAddress
+}
+
+func enabledActivitySet(campaign config.CampaignConfig) map[string]bool {
+	set := make(map[string]bool)
+	for _, activity := range campaign.EnabledActivities() {
+		set[activity.ID] = true
+	}
+	return set
+}
+
+// valueOrNone 返回变更说明中的取值，空值显示为 (无)
+func valueOrNone(s string) string {
+	if s == "" {
+		return "(无)"
+	}
+	return s
+}
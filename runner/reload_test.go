@@ -0,0 +1,34 @@
+package runner
+
+import (
+	"reflect"
+	"testing"
+
+	"blockmesh/config"
+)
+
+func TestConfigDiffDescribesEmptyValues(t *testing.T) {
+	old := &config.Config{
+		Accounts:  []config.AccountConfig{{PrivateKey: testKeys[0], Label: "main"}},
+		Campaign:  config.CampaignConfig{ID: "c1"},
+		AuditFile: "signatures.jsonl",
+	}
+	new := &config.Config{
+		Accounts: []config.AccountConfig{{PrivateKey: testKeys[0], Tags: []string{"eu"}}},
+	}
+	want := []string{
+		"账户 #1 标签修改为 [eu]",
+		"活动ID修改为 (无)",
+		"签名审计日志修改为 (无)",
+	}
+	if got := configDiff(old, new); !reflect.DeepEqual(got, want) {
+		t.Errorf("configDiff 返回 %q，期望 %q", got, want)
+	}
+
+	old, new = new, old
+	new.Accounts[0].Label = ""
+	new.Accounts[0].Tags = nil
+	if got := configDiff(old, new); len(got) == 0 || got[0] != "账户 #1 标签修改为 (无)" {
+		t.Errorf("标签清空时应显示 (无)，实际 %q", got)
+	}
+}
@@ -0,0 +1,350 @@
+// Package runner 调度多个账户的签到流程：登录 Privy 与 Deform、领取配置中的活动，
+// 并维护账户状态、奖励账本与登录会话缓存。
+//
+// 典型用法：
+//
+//	cfg, err := config.Load("config.yaml", config.LoadOptions{})
+//	r, err := runner.New("config.yaml", cfg)
+//	results, err := r.Run(ctx, nil)
+package runner
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+
+	"blockmesh/config"
+	"blockmesh/internal/httpclient"
+	"blockmesh/internal/logging"
+	"blockmesh/signer"
+)
+
+// ErrRunInProgress 已有任务在执行时返回
+var ErrRunInProgress = errors.New("已有任务正在执行")
+
+// AccountFilter 决定某个账户是否参与本次执行
+type AccountFilter func(index int, account config.AccountConfig) bool
+
+// AccountStatus 账户概览，供管理 API 使用。Index 为从 1 开始的账户序号，与日志和 -only 一致
+type AccountStatus struct {
+	Index                  int            `json:"index"`
+	Address                string         `json:"address"`
+	Label                  string         `json:"label,omitempty"`
+	Tags                   []string       `json:"tags,omitempty"`
+	Proxy                  string         `json:"proxy,omitempty"`
+	LastResult             *AccountResult `json:"last_result,omitempty"`
+	ConsecutiveFailureDays int            `json:"consecutive_failure_days"`
+}
+
+// Runner 负责调度账户执行，保证同一时间只有一次执行
+type Runner struct {
+	mu              sync.Mutex
+	configPath      string
+	config          *config.Config
+	state           *StateStore
+	ledger          *Ledger
+	sessions        *SessionStore
+	providers       Providers
+	customProviders bool
+	pool            *httpclient.Pool
+	wrapper         httpclient.Wrapper
+	audit           *signer.AuditLog
+	onResult        func(*AccountResult)
+	running         bool
+	cancel          context.CancelFunc
+	lastRunAt       time.Time
+}
+
+// New 创建调度器并加载账户状态、奖励账本与会话缓存
+func New(configPath string, cfg *config.Config) (*Runner, error) {
+	state, err := LoadState(cfg.StateFile)
+	if err != nil {
+		return nil, err
+	}
+	ledger, err := OpenLedger(cfg.LedgerFile)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := LoadSessions(cfg.SessionFile, cfg.EncryptionPassphrase())
+	if err != nil {
+		return nil, err
+	}
+	r := &Runner{
+		configPath: configPath,
+		config:     cfg,
+		state:      state,
+		ledger:     ledger,
+		sessions:   sessions,
+		pool:       httpclient.NewPool(),
+		audit:      signer.NewAuditLog(cfg.AuditFile),
+	}
+	r.providers = DefaultProviders(cfg.Endpoints, r.clientOptions())
+	return r, nil
+}
+
+// clientOptions 返回按当前配置创建客户端的设置，调用方需持有锁或尚未共享 r
+func (r *Runner) clientOptions() ClientOptions {
+	return ClientOptions{
+		HTTP:  httpclient.New(r.pool, httpTimeouts(r.config), r.wrapper),
+		Audit: r.audit,
+	}
+}
+
+// ClientOptions 返回调度器创建 Privy 与 Deform 客户端使用的设置，供预演等直接使用客户端的场景
+func (r *Runner) ClientOptions() ClientOptions {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.clientOptions()
+}
+
+// SetTransportWrapper 设置包装传输层的函数，用于录制或回放请求，为 nil 时直接访问网络。
+// 只影响按 endpoints 创建的默认实现
+func (r *Runner) SetTransportWrapper(wrap httpclient.Wrapper) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.wrapper = wrap
+	if !r.customProviders {
+		r.providers = DefaultProviders(r.config.Endpoints, r.clientOptions())
+	}
+}
+
+// SetAuditLog 替换签名审计日志，为 nil 时不记录 (例如回放)，之后重新加载配置也保持不记录
+func (r *Runner) SetAuditLog(log *signer.AuditLog) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.audit = log
+	if !r.customProviders {
+		r.providers = DefaultProviders(r.config.Endpoints, r.clientOptions())
+	}
+}
+
+// SetProviders 替换登录服务与活动后端，例如接入其他服务或测试替身。
+// 替换后重新加载配置时不再按 endpoints 重建
+func (r *Runner) SetProviders(providers Providers) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers = providers
+	r.customProviders = true
+}
+
+// Providers 返回当前使用的登录服务与活动后端
+func (r *Runner) Providers() Providers {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.providers
+}
+
+// Config 返回当前生效的配置
+func (r *Runner) Config() *config.Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.config
+}
+
+// State 返回账户状态
+func (r *Runner) State() *StateStore {
+	return r.state
+}
+
+// Running 返回是否有任务正在执行
+func (r *Runner) Running() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.running
+}
+
+// OnResult 设置每个账户执行完成后的回调，用于逐个输出结果；为 nil 时不回调
+func (r *Runner) OnResult(fn func(*AccountResult)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onResult = fn
+}
+
+// LastRunAt 返回最近一次执行的开始时间
+func (r *Runner) LastRunAt() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lastRunAt
+}
+
+// Run 依次执行所有通过过滤的账户，filter 为 nil 时执行全部账户
+func (r *Runner) Run(ctx context.Context, filter AccountFilter) ([]*AccountResult, error) {
+	ctx, cfg, err := r.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.execute(ctx, cfg, filter), nil
+}
+
+// Start 在后台启动一次执行，已有任务在执行时返回 ErrRunInProgress
+func (r *Runner) Start(ctx context.Context, filter AccountFilter, done func([]*AccountResult)) error {
+	ctx, cfg, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	go func() {
+		results := r.execute(ctx, cfg, filter)
+		if done != nil {
+			done(results)
+		}
+	}()
+	return nil
+}
+
+// begin 标记任务开始执行
+func (r *Runner) begin(ctx context.Context) (context.Context, *config.Config, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running {
+		return nil, nil, ErrRunInProgress
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	r.running = true
+	r.cancel = cancel
+	r.lastRunAt = time.Now()
+	return ctx, r.config, nil
+}
+
+// execute 执行账户流程，结束后保存状态、发送通知并清除执行状态
+func (r *Runner) execute(ctx context.Context, cfg *config.Config, filter AccountFilter) []*AccountResult {
+	startedAt := time.Now()
+	defer func() {
+		r.mu.Lock()
+		r.cancel()
+		r.running = false
+		r.cancel = nil
+		r.mu.Unlock()
+	}()
+
+	opts := processOptions{
+		Activities: cfg.Campaign.EnabledActivities(),
+		Retries:    cfg.ActivityRetries(),
+		Sessions:   r.sessions,
+		State:      r.state,
+		Providers:  r.Providers(),
+		Clock:      cfg.Clock,
+	}
+	r.mu.Lock()
+	onResult := r.onResult
+	r.mu.Unlock()
+	var results []*AccountResult
+	for i, account := range cfg.Accounts {
+		if filter != nil && !filter(i, account) {
+			continue
+		}
+		if ctx.Err() != nil {
+			logging.Warning("执行已取消，跳过剩余账户")
+			break
+		}
+		result := processAccount(ctx, i, account, opts)
+		results = append(results, result)
+		r.state.Record(result)
+		for _, activity := range result.Activities {
+			if err := r.ledger.RecordActivity(result.Address, result.FinishedAt, activity.Result); err != nil {
+				logging.Error("记录奖励失败: %v", err)
+			}
+		}
+		if onResult != nil {
+			onResult(result)
+		}
+	}
+
+	if err := r.state.Save(); err != nil {
+		logging.Error("保存状态失败: %v", err)
+	}
+	if err := r.sessions.Save(); err != nil {
+		logging.Error("保存会话缓存失败: %v", err)
+	}
+
+	if len(cfg.Notifiers) > 0 && len(results) > 0 {
+		summary := newRunSummary(startedAt, results, r.state)
+		notifyCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		sendNotifications(notifyCtx, cfg.Notifiers, summary)
+		cancel()
+	}
+	return results
+}
+
+// Cancel 取消正在执行的任务，没有任务时返回 false
+func (r *Runner) Cancel() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.running || r.cancel == nil {
+		return false
+	}
+	r.cancel()
+	return true
+}
+
+// Reload 重新加载配置文件，校验通过后才替换当前配置，执行期间不允许重载
+//
+// 状态与会话按地址保存，仍在配置中的账户保留原有状态与会话，已移除账户的会话会被删除
+func (r *Runner) Reload() (*config.Config, error) {
+	r.mu.Lock()
+	opts := r.config.LoadOptions()
+	r.mu.Unlock()
+	cfg, err := config.Load(r.configPath, opts)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running {
+		return nil, ErrRunInProgress
+	}
+	old := r.config
+	r.config = cfg
+	if r.audit != nil {
+		r.audit = signer.NewAuditLog(cfg.AuditFile)
+	}
+	if !r.customProviders {
+		r.providers = DefaultProviders(cfg.Endpoints, r.clientOptions())
+	}
+	r.pool.CloseIdleConnections()
+
+	changes := configDiff(old, cfg)
+	if len(changes) == 0 {
+		logging.Info("配置已重新加载，没有变化")
+	} else {
+		logging.Info("配置已重新加载，%d 处变化:", len(changes))
+		for _, change := range changes {
+			logging.Info("  %s", change)
+		}
+	}
+	if removed := r.sessions.Retain(accountsByAddress(cfg.Accounts)); removed > 0 {
+		if err := r.sessions.Save(); err != nil {
+			logging.Warning("保存会话缓存失败: %v", err)
+		}
+	}
+	return cfg, nil
+}
+
+// Accounts 返回所有账户及其最近一次执行结果
+func (r *Runner) Accounts() []AccountStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	statuses := make([]AccountStatus, 0, len(r.config.Accounts))
+	for i, account := range r.config.Accounts {
+		status := AccountStatus{Index: i + 1, Label: account.Label, Tags: account.Tags, Proxy: RedactProxy(account.Proxy)}
+		if address, err := signer.GetAddressFromPrivateKey(account.PrivateKey); err == nil {
+			status.Address = address
+			if state := r.state.Get(address); state != nil {
+				status.LastResult = state.LastResult
+				status.ConsecutiveFailureDays = state.ConsecutiveFailureDays
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// RedactProxy 隐藏代理地址中的密码
+func RedactProxy(proxyURL string) string {
+	u, err := url.Parse(proxyURL)
+	if err != nil || u.User == nil {
+		return proxyURL
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,358 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"blockmesh/config"
+	"blockmesh/deform"
+	"blockmesh/fakeserver"
+	"blockmesh/privy"
+	"blockmesh/signer"
+)
+
+// 测试账户私钥 (Hardhat 默认账户)，仅用于假服务
+var testKeys = []string{
+	"0x59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d",
+	"0x5de4111afa1a4b94908f83103eb1f1706367c2e68ca870fc3fb9a804cdab365a",
+}
+
+const testActivity = "304a9530-3720-45c8-a778-fbd3060d5cfd"
+
+func init() {
+	activityDelay = 0
+	retryBackoff = 0
+}
+
+// testEnv 指向假服务的调度器，状态文件都在临时目录中
+type testEnv struct {
+	server *fakeserver.Server
+	runner *Runner
+	config *config.Config
+}
+
+func newTestEnv(t *testing.T, opts fakeserver.Options, accounts int, activities ...string) *testEnv {
+	t.Helper()
+	server := fakeserver.New(opts)
+	ts := httptest.NewServer(server.Handler())
+	t.Cleanup(ts.Close)
+
+	if len(activities) == 0 {
+		activities = []string{testActivity}
+	}
+	dir := t.TempDir()
+	retries := 1
+	cfg := &config.Config{
+		Endpoints: config.EndpointsConfig{
+			PrivyBaseURL: ts.URL + "/api/v1",
+			DeformAPIURL: ts.URL + "/",
+		},
+		StateFile:   filepath.Join(dir, "state.json"),
+		LedgerFile:  filepath.Join(dir, "rewards.jsonl"),
+		SessionFile: filepath.Join(dir, "sessions.json"),
+		AuditFile:   filepath.Join(dir, "signatures.jsonl"),
+		Retries:     &retries,
+	}
+	for i := 0; i < accounts; i++ {
+		cfg.Accounts = append(cfg.Accounts, config.AccountConfig{PrivateKey: testKeys[i]})
+	}
+	for _, id := range activities {
+		cfg.Campaign.Activities = append(cfg.Campaign.Activities, config.ActivityConfig{ID: id})
+	}
+
+	r, err := New("", cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return &testEnv{server: server, runner: r, config: cfg}
+}
+
+func (e *testEnv) run(t *testing.T) []*AccountResult {
+	t.Helper()
+	results, err := e.runner.Run(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	return results
+}
+
+func requireSuccess(t *testing.T, results []*AccountResult) {
+	t.Helper()
+	for _, result := range results {
+		if !result.Success {
+			t.Fatalf("账户 %d 执行失败: %s %+v", result.Index, result.Error, result.Activities)
+		}
+	}
+}
+
+func TestRunLoginAndClaim(t *testing.T) {
+	env := newTestEnv(t, fakeserver.Options{}, 2)
+	results := env.run(t)
+	if len(results) != 2 {
+		t.Fatalf("应有 2 个账户结果，实际 %d 个", len(results))
+	}
+	requireSuccess(t, results)
+
+	for _, result := range results {
+		if len(result.Activities) != 1 || result.Activities[0].Outcome != deform.OutcomeCompleted {
+			t.Fatalf("活动结果不正确: %+v", result.Activities)
+		}
+		if got := env.server.Points(result.Address); got != fakeserver.DefaultPoints {
+			t.Errorf("%s 积分为 %d，期望 %d", result.Address, got, fakeserver.DefaultPoints)
+		}
+		if got := env.runner.State().Completions(result.Address, testActivity); got != 1 {
+			t.Errorf("%s 的领取次数为 %d，期望 1", result.Address, got)
+		}
+	}
+	if got := env.server.Calls("authenticate"); got != 2 {
+		t.Errorf("认证接口调用 %d 次，期望 2 次", got)
+	}
+
+	ledger, err := OpenLedger(env.config.LedgerFile)
+	if err != nil {
+		t.Fatalf("OpenLedger: %v", err)
+	}
+	if entries, _ := ledger.Entries(); len(entries) != 2 {
+		t.Errorf("奖励账本应有 2 条记录，实际 %d 条", len(entries))
+	}
+	entries, err := signer.NewAuditLog(env.config.AuditFile).Entries()
+	if err != nil {
+		t.Fatalf("读取签名审计日志: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Purpose != signer.PurposeLogin {
+		t.Errorf("签名审计日志不正确: %+v", entries)
+	}
+
+	// 第二次执行复用缓存的会话，不再签名登录
+	requireSuccess(t, env.run(t))
+	if got := env.server.Calls("authenticate"); got != 2 {
+		t.Errorf("复用会话后认证接口调用 %d 次，期望仍为 2 次", got)
+	}
+}
+
+func TestRunRefetchesExpiringNonce(t *testing.T) {
+	// nonce 有效期短于刷新余量，获取后立即重新获取
+	env := newTestEnv(t, fakeserver.Options{NonceTTL: 3}, 1)
+	requireSuccess(t, env.run(t))
+	if got := env.server.Calls("init"); got != 1+maxNonceRefetches {
+		t.Errorf("初始化接口调用 %d 次，期望重新获取 %d 次后使用仍未过期的 nonce", got, maxNonceRefetches)
+	}
+	if got := env.server.Calls("authenticate"); got != 1 {
+		t.Errorf("认证接口调用 %d 次，期望 1 次", got)
+	}
+}
+
+func TestRunRefetchDoesNotCountAsAttempt(t *testing.T) {
+	// 主动重新获取 nonce 不占用认证重试次数，前两次认证失败后第三次仍可成功
+	env := newTestEnv(t, fakeserver.Options{NonceTTL: 3, InvalidNonces: 2}, 1)
+	requireSuccess(t, env.run(t))
+	if got := env.server.Calls("authenticate"); got != maxNonceAttempts {
+		t.Errorf("认证接口调用 %d 次，期望 %d 次", got, maxNonceAttempts)
+	}
+}
+
+func TestRunJudgesNonceExpiryByServerClock(t *testing.T) {
+	// 服务器时钟慢 1 小时，按本地时间 nonce 早已过期，按服务器时间仍然有效，不应重新获取
+	env := newTestEnv(t, fakeserver.Options{ClockOffset: -3600}, 1)
+	requireSuccess(t, env.run(t))
+	if got := env.server.Calls("init"); got != 1 {
+		t.Errorf("初始化接口调用 %d 次，期望 1 次", got)
+	}
+}
+
+func TestRunRetriesInvalidNonce(t *testing.T) {
+	tests := []struct {
+		name string
+		opts fakeserver.Options
+	}{
+		{"invalid", fakeserver.Options{InvalidNonces: 2}},
+		{"expired", fakeserver.Options{ExpireNonces: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t, tt.opts, 1)
+			requireSuccess(t, env.run(t))
+			if got := env.server.Calls("authenticate"); got != 3 {
+				t.Errorf("认证接口调用 %d 次，期望 3 次", got)
+			}
+			if got := env.server.Calls("init"); got != 3 {
+				t.Errorf("初始化接口调用 %d 次，期望 3 次", got)
+			}
+		})
+	}
+
+	t.Run("exhausted", func(t *testing.T) {
+		env := newTestEnv(t, fakeserver.Options{InvalidNonces: 10}, 1)
+		results := env.run(t)
+		if results[0].Success || !strings.Contains(results[0].Error, "nonce") {
+			t.Fatalf("nonce 一直无效时应登录失败: %+v", results[0])
+		}
+		if got := env.server.Calls("authenticate"); got != maxNonceAttempts {
+			t.Errorf("认证接口调用 %d 次，期望 %d 次", got, maxNonceAttempts)
+		}
+	})
+}
+
+func TestRunRenewsExpiredDeformToken(t *testing.T) {
+	env := newTestEnv(t, fakeserver.Options{}, 1)
+	requireSuccess(t, env.run(t))
+
+	// 假服务时钟前进 2 小时，缓存的 Deform Token 在服务端过期，但本地仍认为有效
+	env.server.SetClock(func() time.Time { return time.Now().Add(2 * time.Hour) })
+	results := env.run(t)
+	requireSuccess(t, results)
+	if got := env.server.Calls("refresh"); got != 1 {
+		t.Errorf("刷新接口调用 %d 次，期望 1 次", got)
+	}
+	if got := env.server.Calls("authenticate"); got != 1 {
+		t.Errorf("续期后认证接口调用 %d 次，期望不重新签名", got)
+	}
+	if got := env.server.Calls("UserLogin"); got != 2 {
+		t.Errorf("Deform 登录接口调用 %d 次，期望 2 次", got)
+	}
+}
+
+func TestRunClassifiesOutcomes(t *testing.T) {
+	activities := map[string]fakeserver.ActivityBehavior{
+		"completed":     {},
+		"already":       {ErrorCode: "ACTIVITY_ALREADY_COMPLETED", ErrorMessage: "Activity already completed"},
+		"ineligible":    {ErrorCode: "NOT_ELIGIBLE", ErrorMessage: "Requirement not met"},
+		"forbidden":     {ErrorCode: "FORBIDDEN", ErrorMessage: "Forbidden"},
+		"flaky":         {FailTimes: 1},
+		"broken":        {FailTimes: 10},
+		"cooldown":      {ErrorCode: "ACTIVITY_COOLDOWN", ErrorMessage: "Activity on cooldown", NextEligibleAt: "2030-01-02T00:00:00Z"},
+		"cooldown-seen": {ErrorCode: "ACTIVITY_COOLDOWN", ErrorMessage: "Activity on cooldown"},
+	}
+	want := map[string]struct {
+		outcome deform.Outcome
+		success bool
+		calls   int
+	}{
+		"completed":     {deform.OutcomeCompleted, true, 1},
+		"already":       {deform.OutcomeAlreadyCompleted, true, 1},
+		"ineligible":    {deform.OutcomeIneligible, false, 1},
+		"forbidden":     {deform.OutcomeFailed, false, 2},
+		"flaky":         {deform.OutcomeCompleted, true, 2},
+		"broken":        {deform.OutcomeFailed, false, 2},
+		"cooldown":      {deform.OutcomeOnCooldown, true, 1},
+		"cooldown-seen": {deform.OutcomeOnCooldown, true, 1},
+	}
+	ids := []string{"completed", "already", "ineligible", "forbidden", "flaky", "broken", "cooldown", "cooldown-seen"}
+	env := newTestEnv(t, fakeserver.Options{Activities: activities}, 1, ids...)
+
+	// 本地记录过 cooldown-seen 的领取，只影响日志说明，冷却中一律跳过
+	address, _ := signer.GetAddressFromPrivateKey(testKeys[0])
+	env.runner.State().Record(&AccountResult{
+		Address:    address,
+		Success:    true,
+		Activities: []ActivityResult{{ActivityID: "cooldown-seen", Success: true, Outcome: deform.OutcomeCompleted}},
+		FinishedAt: time.Now().Add(-time.Hour),
+	})
+
+	result := env.run(t)[0]
+	if result.Success {
+		t.Fatal("有活动失败时账户结果应为失败")
+	}
+	if len(result.Activities) != len(ids) {
+		t.Fatalf("应有 %d 个活动结果，实际 %d 个", len(ids), len(result.Activities))
+	}
+	for _, activity := range result.Activities {
+		expected := want[activity.ActivityID]
+		if activity.Outcome != expected.outcome || activity.Success != expected.success {
+			t.Errorf("%s: 结果为 %s/%v，期望 %s/%v (%s)", activity.ActivityID, activity.Outcome, activity.Success,
+				expected.outcome, expected.success, activity.Error)
+		}
+	}
+	// 只有 failed 会重试 (retries = 1)
+	total := 0
+	for _, expected := range want {
+		total += expected.calls
+	}
+	if got := env.server.Calls("VerifyActivity"); got != total {
+		t.Errorf("领取接口调用 %d 次，期望 %d 次", got, total)
+	}
+	if activity := result.Activities[6]; activity.NextEligibleAt == nil || activity.NextEligibleAt.Year() != 2030 {
+		t.Errorf("冷却结果应带下次可领取时间: %+v", activity)
+	}
+}
+
+func TestRunCooldownDoesNotFailAccount(t *testing.T) {
+	activities := map[string]fakeserver.ActivityBehavior{
+		"daily": {ErrorCode: "ACTIVITY_COOLDOWN", ErrorMessage: "Activity on cooldown", NextEligibleAt: "2030-01-02T00:00:00Z"},
+	}
+	env := newTestEnv(t, fakeserver.Options{Activities: activities}, 1, "daily")
+	result := env.run(t)[0]
+	if !result.Success {
+		t.Fatalf("本地没有领取记录时，冷却中的活动也不应导致账户失败: %+v", result)
+	}
+	if activity := result.Activities[0]; activity.Outcome != deform.OutcomeOnCooldown || activity.NextEligibleAt == nil {
+		t.Errorf("冷却结果应为 on_cooldown 并带下次可领取时间: %+v", activity)
+	}
+	if completions := env.runner.State().Completions(result.Address, "daily"); completions != 0 {
+		t.Errorf("冷却中的活动不应计入领取次数，实际 %d", completions)
+	}
+}
+
+// config 包不依赖客户端包，可配置超时的阶段需要与客户端的阶段名保持一致
+func TestTimeoutStagesMatchClients(t *testing.T) {
+	stages := []string{
+		privy.StageInit,
+		privy.StageAuthenticate,
+		privy.StageRefresh,
+		deform.StageLogin,
+		deform.StageVerifyActivity,
+		deform.StageUserProfile,
+		deform.StageCampaign,
+	}
+	if !reflect.DeepEqual(config.TimeoutStages, stages) {
+		t.Errorf("config.TimeoutStages = %v，客户端阶段为 %v", config.TimeoutStages, stages)
+	}
+}
+
+func TestAccountsIndexStartsAtOne(t *testing.T) {
+	env := newTestEnv(t, fakeserver.Options{}, 2)
+	requireSuccess(t, env.run(t))
+	for i, status := range env.runner.Accounts() {
+		if status.Index != i+1 {
+			t.Errorf("第 %d 个账户的 index 为 %d，期望 %d", i+1, status.Index, i+1)
+		}
+		if status.LastResult == nil || status.LastResult.Index != status.Index {
+			t.Errorf("第 %d 个账户最近一次结果的 index 与账户序号不一致: %+v", i+1, status.LastResult)
+		}
+	}
+}
+
+func TestRunnersKeepSettingsSeparate(t *testing.T) {
+	first := newTestEnv(t, fakeserver.Options{}, 1)
+	second := newTestEnv(t, fakeserver.Options{}, 1)
+	second.runner.SetAuditLog(nil)
+	var wrapped atomic.Int32
+	second.runner.SetTransportWrapper(func(base http.RoundTripper) http.RoundTripper {
+		wrapped.Add(1)
+		return base
+	})
+
+	requireSuccess(t, first.run(t))
+	if wrapped.Load() != 0 {
+		t.Errorf("另一个调度器的传输层包装不应影响本调度器")
+	}
+	requireSuccess(t, second.run(t))
+	if wrapped.Load() == 0 {
+		t.Errorf("传输层包装未生效")
+	}
+
+	if entries, err := signer.NewAuditLog(first.config.AuditFile).Entries(); err != nil || len(entries) != 1 {
+		t.Errorf("第一个调度器应记录 1 条签名，实际 %d 条 (%v)", len(entries), err)
+	}
+	if _, err := os.Stat(second.config.AuditFile); !os.IsNotExist(err) {
+		t.Errorf("关闭审计日志的调度器不应写入签名审计日志: %v", err)
+	}
+}
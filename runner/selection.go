@@ -0,0 +1,103 @@
+package runner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"blockmesh/config"
+	"blockmesh/signer"
+)
+
+// AccountSelector 命令行中的账户选择条件，序号从 1 开始
+type AccountSelector struct {
+	// Only 只执行匹配的账户，可以是序号、地址或标签
+	Only []string
+	// Skip 跳过匹配的账户，可以是序号、地址或标签
+	Skip []string
+	// From、To 序号范围 (包含两端)，0 表示不限
+	From, To int
+	// Tags 只执行带有任一标签的账户
+	Tags []string
+	// FailedLastRun 只执行上次执行失败的账户
+	FailedLastRun bool
+}
+
+// Empty 判断是否未设置任何选择条件
+func (s AccountSelector) Empty() bool {
+	return len(s.Only) == 0 && len(s.Skip) == 0 && s.From == 0 && s.To == 0 && len(s.Tags) == 0 && !s.FailedLastRun
+}
+
+// Filter 根据选择条件生成账户过滤函数，state 用于判断上次执行结果
+func (s AccountSelector) Filter(state *StateStore) (AccountFilter, error) {
+	if s.Empty() {
+		return nil, nil
+	}
+	if s.From < 0 || s.To < 0 {
+		return nil, fmt.Errorf("-from 与 -to 必须是正整数")
+	}
+	if s.To > 0 && s.From > s.To {
+		return nil, fmt.Errorf("-from %d 大于 -to %d", s.From, s.To)
+	}
+
+	return func(index int, account config.AccountConfig) bool {
+		position := index + 1
+		if s.From > 0 && position < s.From {
+			return false
+		}
+		if s.To > 0 && position > s.To {
+			return false
+		}
+
+		address, _ := signer.GetAddressFromPrivateKey(account.PrivateKey)
+		if len(s.Only) > 0 && !matchAccount(s.Only, position, address, account) {
+			return false
+		}
+		if matchAccount(s.Skip, position, address, account) {
+			return false
+		}
+		if len(s.Tags) > 0 && !account.HasAnyTag(s.Tags) {
+			return false
+		}
+		if s.FailedLastRun {
+			if address == "" {
+				return false
+			}
+			accountState := state.Get(address)
+			if accountState == nil || accountState.LastResult == nil || accountState.LastResult.Success {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// matchAccount 判断账户是否匹配任一序号、地址或标签，地址与标签不区分大小写
+func matchAccount(patterns []string, position int, address string, account config.AccountConfig) bool {
+	for _, pattern := range patterns {
+		if n, err := strconv.Atoi(pattern); err == nil && n == position {
+			return true
+		}
+		if address != "" && strings.EqualFold(pattern, address) {
+			return true
+		}
+		if account.Label != "" && strings.EqualFold(pattern, account.Label) {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectedAccounts 返回通过过滤的账户数量，filter 为 nil 时返回全部账户数量
+func SelectedAccounts(accounts []config.AccountConfig, filter AccountFilter) int {
+	if filter == nil {
+		return len(accounts)
+	}
+	n := 0
+	for i, account := range accounts {
+		if filter(i, account) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"blockmesh/config"
+	"blockmesh/signer"
+)
+
+// selectionAccounts 四个测试账户：#1 main [eu]，#2 [us]，#3 backup [eu,vps]，#4 无标签
+func selectionAccounts(t *testing.T) ([]config.AccountConfig, []string) {
+	t.Helper()
+	accounts := []config.AccountConfig{
+		{PrivateKey: "0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", Label: "main", Tags: []string{"eu"}},
+		{PrivateKey: testKeys[0], Tags: []string{"us"}},
+		{PrivateKey: testKeys[1], Label: "backup", Tags: []string{"eu", "vps"}},
+		{PrivateKey: "0x7c852118294e51e653712a81e05800f419141751be58f605c371e15141b007a6"},
+	}
+	addresses := make([]string, len(accounts))
+	for i, account := range accounts {
+		address, err := signer.GetAddressFromPrivateKey(account.PrivateKey)
+		if err != nil {
+			t.Fatalf("推导地址失败: %v", err)
+		}
+		addresses[i] = address
+	}
+	return accounts, addresses
+}
+
+// selectedPositions 返回通过过滤的账户序号 (从 1 开始)
+func selectedPositions(accounts []config.AccountConfig, filter AccountFilter) []int {
+	var positions []int
+	for i, account := range accounts {
+		if filter == nil || filter(i, account) {
+			positions = append(positions, i+1)
+		}
+	}
+	return positions
+}
+
+func TestAccountSelectorFilter(t *testing.T) {
+	accounts, addresses := selectionAccounts(t)
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	state.Record(&AccountResult{Address: addresses[0], Success: true, FinishedAt: now})
+	state.Record(&AccountResult{Address: addresses[1], Success: false, FinishedAt: now})
+	state.Record(&AccountResult{Address: addresses[2], Success: false, FinishedAt: now})
+
+	tests := []struct {
+		name     string
+		selector AccountSelector
+		want     []int
+	}{
+		{"无条件", AccountSelector{}, []int{1, 2, 3, 4}},
+		{"按序号", AccountSelector{Only: []string{"2", "4"}}, []int{2, 4}},
+		{"按地址忽略大小写", AccountSelector{Only: []string{strings.ToLower(addresses[2])}}, []int{3}},
+		{"按标签名", AccountSelector{Only: []string{"main"}}, []int{1}},
+		{"按标签名忽略大小写", AccountSelector{Only: []string{"Main"}}, []int{1}},
+		{"跳过标签名忽略大小写", AccountSelector{Skip: []string{"MAIN"}}, []int{2, 3, 4}},
+		{"跳过", AccountSelector{Skip: []string{"backup", "1"}}, []int{2, 4}},
+		{"序号范围", AccountSelector{From: 2, To: 3}, []int{2, 3}},
+		{"只设起点", AccountSelector{From: 3}, []int{3, 4}},
+		{"按 tag", AccountSelector{Tags: []string{"EU"}}, []int{1, 3}},
+		{"上次失败", AccountSelector{FailedLastRun: true}, []int{2, 3}},
+		{"条件组合", AccountSelector{Tags: []string{"eu"}, FailedLastRun: true, Skip: []string{"2"}}, []int{3}},
+		{"没有匹配", AccountSelector{Only: []string{"9"}}, nil},
+	}
+	for _, test := range tests {
+		filter, err := test.selector.Filter(state)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got := selectedPositions(accounts, filter); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: 选中 %v，期望 %v", test.name, got, test.want)
+		}
+		if got := SelectedAccounts(accounts, filter); got != len(test.want) {
+			t.Errorf("%s: SelectedAccounts = %d，期望 %d", test.name, got, len(test.want))
+		}
+	}
+}
+
+func TestAccountSelectorInvalidRange(t *testing.T) {
+	for _, selector := range []AccountSelector{{From: -1}, {To: -2}, {From: 3, To: 2}} {
+		if _, err := selector.Filter(nil); err == nil {
+			t.Errorf("%+v 应返回错误", selector)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package runner
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"blockmesh/config"
+)
+
+// defaultSessionFile 默认会话缓存文件路径
+const defaultSessionFile = "sessions.json"
+
+// sessionExpiryMargin 会话剩余有效期小于该值时不再复用
+const sessionExpiryMargin = 5 * time.Minute
+
+// CachedSession 缓存的登录会话
+type CachedSession struct {
+	Session
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Valid 判断会话在 now 时刻是否仍可复用
+func (c *CachedSession) Valid(now time.Time) bool {
+	return now.Add(sessionExpiryMargin).Before(c.ExpiresAt)
+}
+
+// SessionStore 按地址缓存 Privy 与 Deform Token，避免每次执行都重新签名登录
+type SessionStore struct {
+	mu         sync.Mutex
+	path       string
+	passphrase string
+	Sessions   map[string]*CachedSession `json:"sessions"`
+}
+
+// LoadSessions 读取会话缓存文件，文件不存在时返回空缓存。
+// passphrase 不为空时 (配置文件已加密) 会话缓存使用相同口令加密保存，旧的明文缓存在下次保存时加密
+func LoadSessions(path, passphrase string) (*SessionStore, error) {
+	if path == "" {
+		path = defaultSessionFile
+	}
+	store := &SessionStore{path: path, passphrase: passphrase, Sessions: make(map[string]*CachedSession)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取会话缓存失败: %v", err)
+	}
+	if envelope, ok := config.ParseEncrypted(data); ok {
+		if passphrase == "" {
+			return nil, fmt.Errorf("会话缓存 %s 已加密，但配置文件未加密，请删除该文件后重新登录", path)
+		}
+		if data, err = envelope.Decrypt(passphrase); err != nil {
+			return nil, fmt.Errorf("解密会话缓存失败: %v", err)
+		}
+		defer clear(data)
+	}
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("解析会话缓存失败: %v", err)
+	}
+	if store.Sessions == nil {
+		store.Sessions = make(map[string]*CachedSession)
+	}
+	return store, nil
+}
+
+// Get 返回地址对应的有效会话，没有或已过期时返回 nil
+func (s *SessionStore) Get(address string) *Session {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cached, ok := s.Sessions[strings.ToLower(address)]
+	if !ok || !cached.Valid(time.Now()) {
+		return nil
+	}
+	session := cached.Session
+	return &session
+}
+
+// Last 返回地址最近缓存的会话，不论是否过期，用于读取刷新 Token
+func (s *SessionStore) Last(address string) *Session {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cached, ok := s.Sessions[strings.ToLower(address)]
+	if !ok {
+		return nil
+	}
+	session := cached.Session
+	return &session
+}
+
+// Put 缓存会话，无法从 Token 中解析过期时间时不缓存
+func (s *SessionStore) Put(session *Session) {
+	if s == nil || session == nil {
+		return
+	}
+	expiresAt, ok := jwtExpiry(session.DeformToken)
+	if !ok {
+		return
+	}
+	if privyExpiry, ok := jwtExpiry(session.IdentityToken); ok && privyExpiry.Before(expiresAt) {
+		expiresAt = privyExpiry
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Sessions[strings.ToLower(session.Address)] = &CachedSession{
+		Session:   *session,
+		CreatedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	}
+}
+
+// Delete 删除地址对应的会话，返回是否存在
+func (s *SessionStore) Delete(address string) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := strings.ToLower(address)
+	_, ok := s.Sessions[key]
+	delete(s.Sessions, key)
+	return ok
+}
+
+// Clear 清空全部会话，返回清除的数量
+func (s *SessionStore) Clear() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.Sessions)
+	s.Sessions = make(map[string]*CachedSession)
+	return n
+}
+
+// Retain 只保留 accounts 中地址的会话，返回删除的数量
+func (s *SessionStore) Retain(accounts map[string]config.AccountConfig) int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	removed := 0
+	for key := range s.Sessions {
+		if _, ok := accounts[key]; !ok {
+			delete(s.Sessions, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// List 按地址排序返回全部会话
+func (s *SessionStore) List() []*CachedSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sessions := make([]*CachedSession, 0, len(s.Sessions))
+	for _, session := range s.Sessions {
+		sessions = append(sessions, session)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Address < sessions[j].Address })
+	return sessions
+}
+
+// Save 将会话缓存写回文件
+func (s *SessionStore) Save() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	data, err := json.MarshalIndent(s, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("序列化会话缓存失败: %v", err)
+	}
+	if s.passphrase != "" {
+		plaintext := data
+		data, err = config.Encrypt(plaintext, ".json", s.passphrase)
+		clear(plaintext)
+		if err != nil {
+			return fmt.Errorf("加密会话缓存失败: %v", err)
+		}
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("写入会话缓存失败: %v", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("写入会话缓存失败: %v", err)
+	}
+	return nil
+}
+
+// jwtExpiry 读取 JWT 中的 exp 字段，不校验签名
+func jwtExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Exp, 0), true
+}
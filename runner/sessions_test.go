@@ -0,0 +1,98 @@
+package runner
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"blockmesh/config"
+)
+
+// testJWT 生成只包含 exp 的未签名 JWT
+func testJWT(exp time.Time) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	return encode([]byte(`{"alg":"none"}`)) + "." + encode([]byte(fmt.Sprintf(`{"exp":%d}`, exp.Unix()))) + ".sig"
+}
+
+func testSession() *Session {
+	exp := time.Now().Add(time.Hour)
+	return &Session{
+		Address:       "0xAbC0000000000000000000000000000000000001",
+		PrivyToken:    "privy-token",
+		IdentityToken: testJWT(exp),
+		RefreshToken:  "refresh-token",
+		DeformToken:   testJWT(exp),
+	}
+}
+
+func TestSessionsEncryptedWithConfigPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.json")
+	store, err := LoadSessions(path, "secret")
+	if err != nil {
+		t.Fatalf("加载会话缓存失败: %v", err)
+	}
+	session := testSession()
+	store.Put(session)
+	if err := store.Save(); err != nil {
+		t.Fatalf("保存会话缓存失败: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := config.ParseEncrypted(data); !ok || strings.Contains(string(data), session.RefreshToken) {
+		t.Fatalf("会话缓存未加密: %s", data)
+	}
+	if info, err := os.Stat(path); err == nil && info.Mode().Perm() != 0600 {
+		t.Errorf("会话缓存权限为 %v，应为 0600", info.Mode().Perm())
+	}
+
+	loaded, err := LoadSessions(path, "secret")
+	if err != nil {
+		t.Fatalf("加载加密会话缓存失败: %v", err)
+	}
+	if got := loaded.Get(session.Address); got == nil || got.RefreshToken != session.RefreshToken {
+		t.Errorf("加密会话缓存读取结果不一致: %+v", got)
+	}
+	if _, err := LoadSessions(path, "wrong"); err == nil {
+		t.Errorf("口令错误时应返回错误")
+	}
+	if _, err := LoadSessions(path, ""); err == nil {
+		t.Errorf("配置未加密时读取加密会话缓存应返回错误")
+	}
+}
+
+func TestSessionsPlaintextMigratedToEncrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.json")
+	store, err := LoadSessions(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := testSession()
+	store.Put(session)
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), session.RefreshToken) {
+		t.Fatalf("未加密配置应保存明文会话缓存")
+	}
+
+	migrated, err := LoadSessions(path, "secret")
+	if err != nil {
+		t.Fatalf("加载明文会话缓存失败: %v", err)
+	}
+	if migrated.Get(session.Address) == nil {
+		t.Fatalf("明文会话缓存未读取")
+	}
+	if err := migrated.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), session.RefreshToken) {
+		t.Errorf("明文会话缓存保存后仍未加密")
+	}
+}
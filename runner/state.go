@@ -0,0 +1,202 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"blockmesh/deform"
+)
+
+// defaultStateFile 默认状态文件路径
+const defaultStateFile = "state.json"
+
+// DayLayout 按天统计时使用的日期格式
+const DayLayout = "2006-01-02"
+
+// AccountState 账户的持久化状态
+type AccountState struct {
+	Address                string         `json:"address"`
+	LastResult             *AccountResult `json:"last_result,omitempty"`
+	LastSuccessDay         string         `json:"last_success_day,omitempty"`
+	LastFailureDay         string         `json:"last_failure_day,omitempty"`
+	ConsecutiveFailureDays int            `json:"consecutive_failure_days"`
+
+	Activities map[string]*ActivityProgress `json:"activities,omitempty"`
+	Missions   map[string]*MissionProgress  `json:"missions,omitempty"`
+}
+
+// ActivityProgress 账户在单个活动上的累计进度
+type ActivityProgress struct {
+	LastStatus      string    `json:"last_status,omitempty"`
+	LastCompletedAt time.Time `json:"last_completed_at,omitempty"`
+	Completions     int       `json:"completions"`
+	MissionID       string    `json:"mission_id,omitempty"`
+	MissionStatus   string    `json:"mission_status,omitempty"`
+}
+
+// MissionStatusCompleted 任务记录的完成状态
+const MissionStatusCompleted = "COMPLETED"
+
+// MissionProgress 账户在单个任务上的进度
+type MissionProgress struct {
+	Status      string    `json:"status,omitempty"`
+	Points      int       `json:"points"`
+	UpdatedAt   time.Time `json:"updated_at,omitempty"`
+	CompletedAt time.Time `json:"completed_at,omitempty"`
+}
+
+// Completed 判断任务是否已完成
+func (p *MissionProgress) Completed() bool {
+	return p.Status == MissionStatusCompleted
+}
+
+// StateStore 保存每个账户最近的执行结果与连续失败天数
+type StateStore struct {
+	mu       sync.Mutex
+	path     string
+	Accounts map[string]*AccountState `json:"accounts"`
+}
+
+// LoadState 读取状态文件，文件不存在时返回空状态
+func LoadState(path string) (*StateStore, error) {
+	if path == "" {
+		path = defaultStateFile
+	}
+	store := &StateStore{path: path, Accounts: make(map[string]*AccountState)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取状态文件失败: %v", err)
+	}
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("解析状态文件失败: %v", err)
+	}
+	if store.Accounts == nil {
+		store.Accounts = make(map[string]*AccountState)
+	}
+	return store, nil
+}
+
+// Get 返回地址对应的状态，不存在时返回 nil
+func (s *StateStore) Get(address string) *AccountState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Accounts[strings.ToLower(address)]
+}
+
+// Completions 返回账户在活动上的累计领取次数，s 为 nil 时返回 0
+func (s *StateStore) Completions(address, activityID string) int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.Accounts[strings.ToLower(address)]
+	if state == nil || state.Activities[activityID] == nil {
+		return 0
+	}
+	return state.Activities[activityID].Completions
+}
+
+// Record 记录一次账户执行结果并更新连续失败天数
+func (s *StateStore) Record(result *AccountResult) {
+	if result.Address == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := strings.ToLower(result.Address)
+	state, ok := s.Accounts[key]
+	if !ok {
+		state = &AccountState{Address: result.Address}
+		s.Accounts[key] = state
+	}
+	state.LastResult = result
+	recordActivityProgress(state, result)
+
+	day := result.FinishedAt.Local().Format(DayLayout)
+	if result.Success {
+		state.LastSuccessDay = day
+		state.ConsecutiveFailureDays = 0
+		return
+	}
+	if state.LastFailureDay == day && state.ConsecutiveFailureDays > 0 {
+		return
+	}
+	yesterday := result.FinishedAt.Local().AddDate(0, 0, -1).Format(DayLayout)
+	if state.LastFailureDay == yesterday && state.ConsecutiveFailureDays > 0 {
+		state.ConsecutiveFailureDays++
+	} else {
+		state.ConsecutiveFailureDays = 1
+	}
+	state.LastFailureDay = day
+}
+
+// recordActivityProgress 根据领取结果更新活动与任务进度
+func recordActivityProgress(state *AccountState, result *AccountResult) {
+	if state.Activities == nil {
+		state.Activities = make(map[string]*ActivityProgress)
+	}
+	for _, activity := range result.Activities {
+		if activity.Outcome != deform.OutcomeCompleted {
+			continue
+		}
+		progress, ok := state.Activities[activity.ActivityID]
+		if !ok {
+			progress = &ActivityProgress{}
+			state.Activities[activity.ActivityID] = progress
+		}
+		progress.LastStatus = activity.Status
+		progress.LastCompletedAt = result.FinishedAt
+		progress.Completions++
+		if activity.MissionID != "" {
+			progress.MissionID = activity.MissionID
+			progress.MissionStatus = activity.MissionStatus
+			recordMissionProgress(state, activity, result.FinishedAt)
+		}
+	}
+}
+
+// recordMissionProgress 根据活动返回的任务记录更新任务状态与累计任务积分
+func recordMissionProgress(state *AccountState, activity ActivityResult, at time.Time) {
+	if state.Missions == nil {
+		state.Missions = make(map[string]*MissionProgress)
+	}
+	mission, ok := state.Missions[activity.MissionID]
+	if !ok {
+		mission = &MissionProgress{}
+		state.Missions[activity.MissionID] = mission
+	}
+	mission.Status = activity.MissionStatus
+	mission.Points += activity.MissionPoints
+	mission.UpdatedAt = at
+	if mission.Completed() && mission.CompletedAt.IsZero() {
+		mission.CompletedAt = at
+	}
+}
+
+// Save 将状态写回文件
+func (s *StateStore) Save() error {
+	s.mu.Lock()
+	data, err := json.MarshalIndent(s, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("序列化状态失败: %v", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("写入状态文件失败: %v", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("写入状态文件失败: %v", err)
+	}
+	return nil
+}
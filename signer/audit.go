@@ -0,0 +1,191 @@
+package signer
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultAuditFile 默认签名审计日志路径
+const DefaultAuditFile = "signatures.jsonl"
+
+// 签名用途
+const (
+	PurposeLogin  = "login"
+	PurposeDryRun = "dry-run"
+)
+
+// genesisHash 第一条记录的 PrevHash
+var genesisHash = strings.Repeat("0", 64)
+
+// auditTailSize 读取最后一条记录时从文件末尾读取的字节数，远大于单条记录的长度
+const auditTailSize = 64 * 1024
+
+// AuditEntry 签名审计日志中的一条记录。
+// Hash 为除 Hash 外全部字段 (含上一条记录的 Hash) 的 SHA-256，修改或删除任意一条记录都会使后续校验失败
+type AuditEntry struct {
+	Seq         int       `json:"seq"`
+	Time        time.Time `json:"time"`
+	Address     string    `json:"address"`
+	Domain      string    `json:"domain"`
+	URI         string    `json:"uri"`
+	ChainID     string    `json:"chain_id"`
+	Nonce       string    `json:"nonce"`
+	MessageHash string    `json:"message_hash"`
+	Purpose     string    `json:"purpose"`
+	PrevHash    string    `json:"prev_hash"`
+	Hash        string    `json:"hash"`
+}
+
+// computeHash 计算记录的哈希
+func (e AuditEntry) computeHash() string {
+	e.Hash = ""
+	data, _ := json.Marshal(e)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditLog 以 JSON Lines 追加写入的签名审计日志，每条记录包含上一条记录的哈希
+type AuditLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewAuditLog 创建签名审计日志，path 为空时使用 DefaultAuditFile。文件在第一次写入时创建
+func NewAuditLog(path string) *AuditLog {
+	if path == "" {
+		path = DefaultAuditFile
+	}
+	return &AuditLog{path: path}
+}
+
+// Path 返回审计日志文件路径
+func (l *AuditLog) Path() string {
+	return l.path
+}
+
+// Append 追加一条记录，自动填写序号、时间 (为空时) 与哈希链
+func (l *AuditLog) Append(entry AuditEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.OpenFile(l.path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("打开签名审计日志失败: %v", err)
+	}
+	defer file.Close()
+
+	last, err := lastAuditEntry(file)
+	if err != nil {
+		return err
+	}
+	entry.Seq = 1
+	entry.PrevHash = genesisHash
+	if last != nil {
+		entry.Seq = last.Seq + 1
+		entry.PrevHash = last.Hash
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	entry.Time = entry.Time.UTC()
+	entry.Hash = entry.computeHash()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("序列化签名审计记录失败: %v", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("写入签名审计日志失败: %v", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("写入签名审计日志失败: %v", err)
+	}
+	return nil
+}
+
+// lastAuditEntry 读取文件中的最后一条记录，文件为空时返回 nil
+func lastAuditEntry(file *os.File) (*AuditEntry, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("读取签名审计日志失败: %v", err)
+	}
+	offset := info.Size() - auditTailSize
+	if offset < 0 {
+		offset = 0
+	}
+	tail := make([]byte, info.Size()-offset)
+	if _, err := file.ReadAt(tail, offset); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("读取签名审计日志失败: %v", err)
+	}
+	lines := bytes.Split(bytes.TrimSpace(tail), []byte("\n"))
+	line := lines[len(lines)-1]
+	if len(line) == 0 {
+		return nil, nil
+	}
+	var entry AuditEntry
+	if err := json.Unmarshal(line, &entry); err != nil {
+		return nil, fmt.Errorf("签名审计日志最后一条记录已损坏: %v", err)
+	}
+	return &entry, nil
+}
+
+// Entries 读取审计日志中的全部记录，不校验哈希链
+func (l *AuditLog) Entries() ([]AuditEntry, error) {
+	file, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开签名审计日志失败: %v", err)
+	}
+	defer file.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("解析签名审计日志第 %d 行失败: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取签名审计日志失败: %v", err)
+	}
+	return entries, nil
+}
+
+// Verify 校验哈希链，返回记录数。发现序号不连续、哈希不匹配或链断开时返回第一处问题
+func (l *AuditLog) Verify() (int, error) {
+	entries, err := l.Entries()
+	if err != nil {
+		return 0, err
+	}
+	prev := genesisHash
+	for i, entry := range entries {
+		if entry.Seq != i+1 {
+			return i, fmt.Errorf("第 %d 条记录的序号为 %d，记录可能被删除或插入", i+1, entry.Seq)
+		}
+		if entry.PrevHash != prev {
+			return i, fmt.Errorf("第 %d 条记录与上一条记录的哈希不匹配，记录可能被删除或替换", entry.Seq)
+		}
+		if entry.computeHash() != entry.Hash {
+			return i, fmt.Errorf("第 %d 条记录的哈希校验失败，记录内容已被修改", entry.Seq)
+		}
+		prev = entry.Hash
+	}
+	return len(entries), nil
+}
@@ -0,0 +1,142 @@
+package signer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testKey = "0x59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d"
+
+// writeAuditLog 写入 n 条记录并返回审计日志
+func writeAuditLog(t *testing.T, n int) *AuditLog {
+	t.Helper()
+	log := NewAuditLog(filepath.Join(t.TempDir(), "signatures.jsonl"))
+	for i := 0; i < n; i++ {
+		err := log.Append(AuditEntry{Address: "0xabc", Domain: "example.com", Nonce: strings.Repeat("n", i+1), Purpose: PurposeLogin})
+		if err != nil {
+			t.Fatalf("写入审计记录失败: %v", err)
+		}
+	}
+	return log
+}
+
+// rewriteLines 按行修改审计日志文件
+func rewriteLines(t *testing.T, log *AuditLog, edit func(lines []string) []string) {
+	t.Helper()
+	data, err := os.ReadFile(log.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := edit(strings.Split(strings.TrimSpace(string(data)), "\n"))
+	if err := os.WriteFile(log.Path(), []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAuditLogChain(t *testing.T) {
+	log := writeAuditLog(t, 3)
+	entries, err := log.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("记录数为 %d，期望 3", len(entries))
+	}
+	if entries[0].Seq != 1 || entries[0].PrevHash != genesisHash {
+		t.Errorf("第一条记录应从创世哈希开始: %+v", entries[0])
+	}
+	for i := 1; i < len(entries); i++ {
+		if entries[i].Seq != i+1 || entries[i].PrevHash != entries[i-1].Hash {
+			t.Errorf("第 %d 条记录没有链接到上一条记录", i+1)
+		}
+	}
+	if n, err := log.Verify(); err != nil || n != 3 {
+		t.Errorf("校验结果 %d, %v，期望 3 条记录校验通过", n, err)
+	}
+}
+
+func TestAuditLogVerifyDetectsTampering(t *testing.T) {
+	tests := []struct {
+		name  string
+		edit  func(lines []string) []string
+		valid int
+		want  string
+	}{
+		{"修改记录", func(lines []string) []string {
+			lines[1] = strings.Replace(lines[1], `"nonce":"nn"`, `"nonce":"xx"`, 1)
+			return lines
+		}, 1, "内容已被修改"},
+		{"删除中间记录", func(lines []string) []string {
+			return append(lines[:1], lines[2:]...)
+		}, 1, "序号"},
+		{"删除第一条记录", func(lines []string) []string {
+			return lines[1:]
+		}, 0, "序号"},
+		{"调换记录", func(lines []string) []string {
+			lines[1], lines[2] = lines[2], lines[1]
+			return lines
+		}, 1, "序号"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			log := writeAuditLog(t, 3)
+			rewriteLines(t, log, test.edit)
+			n, err := log.Verify()
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("应检测到篡改 (%s)，实际 %v", test.want, err)
+			}
+			if n != test.valid {
+				t.Errorf("完好记录数为 %d，期望 %d", n, test.valid)
+			}
+		})
+	}
+
+	t.Run("删除记录后重算序号与哈希", func(t *testing.T) {
+		// 修正序号并重算本条哈希，但 PrevHash 仍指向被删除的记录
+		log := writeAuditLog(t, 3)
+		entries, err := log.Entries()
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries[2].Seq = 2
+		entries[2].Hash = entries[2].computeHash()
+		data, err := json.Marshal(entries[2])
+		if err != nil {
+			t.Fatal(err)
+		}
+		rewriteLines(t, log, func(lines []string) []string {
+			return []string{lines[0], string(data)}
+		})
+		if n, err := log.Verify(); err == nil || !strings.Contains(err.Error(), "哈希不匹配") || n != 1 {
+			t.Errorf("应检测到哈希链断开，实际 %d, %v", n, err)
+		}
+	})
+}
+
+func TestSignWritesAuditEntryFirst(t *testing.T) {
+	log := NewAuditLog(filepath.Join(t.TempDir(), "signatures.jsonl"))
+
+	address, err := GetAddressFromPrivateKey(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := SignEIP4361Message(testKey, "example.com", address, "", "https://example.com", "1", "1", "nonce123", "2026-01-01T00:00:00Z", nil, log, PurposeDryRun); err != nil {
+		t.Fatalf("签名失败: %v", err)
+	}
+	entries, err := log.Entries()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("应写入 1 条审计记录，实际 %d 条 (%v)", len(entries), err)
+	}
+	if entries[0].Nonce != "nonce123" || entries[0].Purpose != PurposeDryRun || entries[0].Address != address {
+		t.Errorf("审计记录内容不一致: %+v", entries[0])
+	}
+
+	// 审计日志无法写入时不返回签名
+	missing := NewAuditLog(filepath.Join(t.TempDir(), "missing", "signatures.jsonl"))
+	if signature, _, err := SignEIP4361Message(testKey, "example.com", address, "", "https://example.com", "1", "1", "nonce123", "2026-01-01T00:00:00Z", nil, missing, PurposeLogin); err == nil || signature != "" {
+		t.Errorf("审计日志写入失败时不应返回签名")
+	}
+}
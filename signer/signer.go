@@ -0,0 +1,88 @@
+// Package signer 管理以太坊私钥：解析私钥、派生地址并对 SIWE 消息签名。
+package signer
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"blockmesh/siwe"
+)
+
+// privateKeyPattern 去掉 0x 前缀后的私钥格式
+var privateKeyPattern = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+// NormalizePrivateKey 去掉私钥两端空白与 0x 前缀
+func NormalizePrivateKey(key string) string {
+	key = strings.TrimSpace(key)
+	if strings.HasPrefix(key, "0x") || strings.HasPrefix(key, "0X") {
+		key = key[2:]
+	}
+	return key
+}
+
+// ValidPrivateKey 判断私钥是否为 64 位十六进制 (可带 0x 前缀)
+func ValidPrivateKey(key string) bool {
+	return privateKeyPattern.MatchString(NormalizePrivateKey(key))
+}
+
+// GetAddressFromPrivateKey 通过私钥获取以太坊钱包地址
+func GetAddressFromPrivateKey(privateKeyHex string) (string, error) {
+	privateKey, err := crypto.HexToECDSA(NormalizePrivateKey(privateKeyHex))
+	if err != nil {
+		return "", fmt.Errorf("无效的私钥格式: %v", err)
+	}
+
+	publicKey := privateKey.Public()
+	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return "", fmt.Errorf("无法获取ECDSA公钥")
+	}
+
+	address := crypto.PubkeyToAddress(*publicKeyECDSA)
+	return address.Hex(), nil
+}
+
+// SignEIP4361Message 生成 EIP-4361 签名，返回 0x 开头的签名与签名的消息。
+// audit 不为 nil 时，会先在审计日志中记录本次签名及用途 purpose
+func SignEIP4361Message(
+	privateKeyHex, domain, address, statement, uri, version, chainID, nonce, issuedAt string,
+	resources []string, audit *AuditLog, purpose string,
+) (string, string, error) {
+	privateKey, err := crypto.HexToECDSA(NormalizePrivateKey(privateKeyHex))
+	if err != nil {
+		return "", "", fmt.Errorf("解析私钥失败: %v", err)
+	}
+
+	message := siwe.FormatEIP4361Message(domain, address, statement, uri, version, chainID, nonce, issuedAt, resources)
+	hashedMessage := siwe.HashMessage(message)
+
+	signature, err := crypto.Sign(hashedMessage, privateKey)
+	if err != nil {
+		return "", "", fmt.Errorf("签名失败: %v", err)
+	}
+
+	// 先写入审计记录，写入失败时不返回签名
+	if audit != nil {
+		err := audit.Append(AuditEntry{
+			Address:     address,
+			Domain:      domain,
+			URI:         uri,
+			ChainID:     chainID,
+			Nonce:       nonce,
+			MessageHash: hexutil.Encode(hashedMessage),
+			Purpose:     purpose,
+		})
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	signatureHex := hex.EncodeToString(signature)
+	return "0x" + signatureHex, message, nil
+}
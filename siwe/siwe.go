@@ -0,0 +1,85 @@
+// Package siwe 实现 EIP-4361 (Sign-In with Ethereum) 消息的格式化与签名校验。
+//
+// 签名使用 EIP-191 personal_sign 格式，私钥签名见 blockmesh/signer。
+package siwe
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TimeLayout Issued At 等时间字段使用的 ISO 8601 格式
+const TimeLayout = "2006-01-02T15:04:05.000Z"
+
+// FormatEIP4361Message 按 EIP-4361 规范生成待签名的消息，statement 与 resources 可为空
+func FormatEIP4361Message(domain, address, statement, uri, version, chainID, nonce, issuedAt string, resources []string) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("%s wants you to sign in with your Ethereum account:\n", domain))
+	sb.WriteString(fmt.Sprintf("%s\n\n", address))
+
+	if statement != "" {
+		sb.WriteString(fmt.Sprintf("%s\n\n", statement))
+	} else {
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("URI: %s\n", uri))
+	sb.WriteString(fmt.Sprintf("Version: %s\n", version))
+	sb.WriteString(fmt.Sprintf("Chain ID: %s\n", chainID))
+	sb.WriteString(fmt.Sprintf("Nonce: %s\n", nonce))
+	sb.WriteString(fmt.Sprintf("Issued At: %s", issuedAt))
+
+	if len(resources) > 0 {
+		sb.WriteString("\nResources:")
+		for _, resource := range resources {
+			sb.WriteString(fmt.Sprintf("\n- %s", resource))
+		}
+	}
+
+	return sb.String()
+}
+
+// HashMessage 返回消息的 EIP-191 哈希，即实际签名的内容
+func HashMessage(message string) []byte {
+	prefix := fmt.Sprintf("\x19Ethereum Signed Message:\n%d", len(message))
+	data := []byte(prefix + message)
+	return crypto.Keccak256([]byte(data))
+}
+
+// VerifyEIP4361Signature 校验签名是否由指定地址对消息签出
+func VerifyEIP4361Signature(message, signatureHex, address string) error {
+	signature, err := hexutil.Decode(signatureHex)
+	if err != nil {
+		return fmt.Errorf("无效的签名格式: %v", err)
+	}
+	if len(signature) != crypto.SignatureLength {
+		return fmt.Errorf("签名长度错误: %d", len(signature))
+	}
+	if signature[crypto.RecoveryIDOffset] >= 27 {
+		signature[crypto.RecoveryIDOffset] -= 27
+	}
+
+	publicKey, err := crypto.SigToPub(HashMessage(message), signature)
+	if err != nil {
+		return fmt.Errorf("恢复公钥失败: %v", err)
+	}
+	if signer := crypto.PubkeyToAddress(*publicKey).Hex(); !strings.EqualFold(signer, address) {
+		return fmt.Errorf("签名地址不匹配: 期望 %s, 实际 %s", address, signer)
+	}
+	return nil
+}
+
+// GetCurrentTimeInISO8601 返回当前 UTC 时间，用作 Issued At
+func GetCurrentTimeInISO8601() string {
+	return FormatTime(time.Now())
+}
+
+// FormatTime 按 TimeLayout 格式化 UTC 时间
+func FormatTime(t time.Time) string {
+	return t.UTC().Format(TimeLayout)
+}
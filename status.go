@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"blockmesh/config"
+	"blockmesh/deform"
+	"blockmesh/internal/httpclient"
+	"blockmesh/internal/logging"
+	"blockmesh/runner"
+	"blockmesh/signer"
+)
+
+// AccountProfile 账户在 Deform 上的积分与排名
+type AccountProfile struct {
+	Index   int
+	Label   string
+	Address string
+	Profile *deform.UserProfile
+	Error   error
+}
+
+// runStatus 登录每个账户并输出积分与排名，不领取任何奖励。
+// 与 run 相同，有账户查询失败时返回 errRunFailed 或 errRunPartial
+func runStatus(ctx context.Context, cfg *config.Config, providers runner.Providers, sessions *runner.SessionStore, w io.Writer) error {
+	if cfg.Campaign.ID == "" {
+		return fmt.Errorf("未配置 campaign.id，无法查询积分与排名")
+	}
+
+	var profiles []AccountProfile
+	for i, account := range cfg.Accounts {
+		if ctx.Err() != nil {
+			break
+		}
+		profiles = append(profiles, queryAccountProfile(ctx, providers, cfg.Clock, cfg.Campaign.ID, i, account, sessions))
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\t标签\t地址\t用户ID\t积分\t排名\t状态")
+	failed := 0
+	for _, p := range profiles {
+		label := valueOrDash(p.Label)
+		switch {
+		case p.Error != nil:
+			failed++
+			fmt.Fprintf(tw, "%d\t%s\t%s\t-\t-\t-\t%v\n", p.Index, label, p.Address, p.Error)
+		case p.Profile.CampaignSpot == nil:
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t0\t-\t未参与活动\n", p.Index, label, p.Address, p.Profile.ID)
+		default:
+			spot := p.Profile.CampaignSpot
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%d\t%d\tOK\n", p.Index, label, p.Address, p.Profile.ID, spot.Points, spot.Rank)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("输出状态失败: %v", err)
+	}
+	return outcome(ctx, failed, len(cfg.Accounts))
+}
+
+// queryAccountProfile 登录单个账户并查询资料，优先复用缓存的会话
+func queryAccountProfile(ctx context.Context, providers runner.Providers, clock config.ClockConfig, campaignID string, index int, account config.AccountConfig, sessions *runner.SessionStore) AccountProfile {
+	result := AccountProfile{Index: index + 1, Label: account.Display()}
+	logging.Info("查询第 %d 个账户%s (代理: %s)", index+1, account.DisplaySuffix(), account.Proxy)
+
+	address, err := signer.GetAddressFromPrivateKey(account.PrivateKey)
+	if err != nil {
+		result.Error = fmt.Errorf("获取地址失败: %v", err)
+		logging.Error("%v", result.Error)
+		return result
+	}
+	result.Address = address
+	ctx = httpclient.WithAccount(ctx, address)
+
+	session := sessions.Get(address)
+	cached := session != nil
+	if !cached {
+		if session, err = runner.RenewSession(ctx, providers, clock, account, address, sessions.Last(address)); err != nil {
+			result.Error = err
+			logging.Error("%v", err)
+			return result
+		}
+		sessions.Put(session)
+	}
+
+	profile, err := providers.Backend.QueryUserProfile(ctx, campaignID, session.DeformToken, session.IdentityToken, account.Proxy)
+	if err != nil && cached {
+		// 缓存的会话可能已被服务端注销，重新登录后再查询一次
+		logging.Warning("使用缓存的会话查询失败，重新登录: %v", err)
+		sessions.Delete(address)
+		if session, err = runner.RenewSession(ctx, providers, clock, account, address, session); err != nil {
+			result.Error = err
+			logging.Error("%v", err)
+			return result
+		}
+		sessions.Put(session)
+		profile, err = providers.Backend.QueryUserProfile(ctx, campaignID, session.DeformToken, session.IdentityToken, account.Proxy)
+	}
+	if err != nil {
+		result.Error = fmt.Errorf("查询用户资料失败: %v", err)
+		logging.Error("%v", result.Error)
+		return result
+	}
+	result.Profile = profile
+	return result
+}
+
+// printMissionProgress 输出每个账户在所有已配置活动上的完成情况，以及每个任务的状态与任务积分
+func printMissionProgress(w io.Writer, cfg *config.Config, state *runner.StateStore) error {
+	activities := cfg.Campaign.EnabledActivities()
+
+	type missionRow struct {
+		index   int
+		label   string
+		address string
+		id      string
+		mission *runner.MissionProgress
+	}
+	var missions []missionRow
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\t标签\t地址\t活动\t完成次数\t最近状态\t最近完成\t任务\t任务状态")
+	for i, account := range cfg.Accounts {
+		label := valueOrDash(account.Display())
+		address, err := signer.GetAddressFromPrivateKey(account.PrivateKey)
+		if err != nil {
+			fmt.Fprintf(tw, "%d\t%s\t-\t-\t-\t-\t-\t-\t获取地址失败: %v\n", i+1, label, err)
+			continue
+		}
+		accountState := state.Get(address)
+		if accountState != nil {
+			ids := make([]string, 0, len(accountState.Missions))
+			for id := range accountState.Missions {
+				ids = append(ids, id)
+			}
+			sort.Strings(ids)
+			for _, id := range ids {
+				missions = append(missions, missionRow{i + 1, label, address, id, accountState.Missions[id]})
+			}
+		}
+		for _, activity := range activities {
+			var progress *runner.ActivityProgress
+			if accountState != nil {
+				progress = accountState.Activities[activity.ID]
+			}
+			name := cfg.Campaign.ActivityName(activity.ID)
+			if progress == nil {
+				fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t0\t-\t-\t-\t-\n", i+1, label, address, name)
+				continue
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\n",
+				i+1, label, address, name, progress.Completions, valueOrDash(progress.LastStatus),
+				progress.LastCompletedAt.Local().Format("2006-01-02 15:04"),
+				valueOrDash(progress.MissionID), valueOrDash(progress.MissionStatus))
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	if len(missions) == 0 {
+		fmt.Fprintln(w, "暂无任务记录")
+		return nil
+	}
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\t标签\t地址\t任务\t状态\t已完成\t任务积分\t完成时间")
+	for _, row := range missions {
+		completed, completedAt := "否", "-"
+		if row.mission.Completed() {
+			completed = "是"
+			completedAt = row.mission.CompletedAt.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n", row.index, row.label, row.address, row.id,
+			valueOrDash(row.mission.Status), completed, row.mission.Points, completedAt)
+	}
+	return tw.Flush()
+}
+
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}